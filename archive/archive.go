@@ -0,0 +1,213 @@
+// Package archive exports and imports flodk executions as portable, versioned JSON-lines
+// archives. The first line of an archive is a [Header], followed by one [Record] per
+// execution holding the complete [flodk.ExecutionState], including the visited nodes and
+// the interrupt history, and the full visit history when a [flodk.HistoryStore] is used.
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aki-kong/flodk"
+)
+
+const (
+	// Format identifies flodk archives.
+	Format = "flodk-archive"
+	// Version is the archive format version written by the [Exporter].
+	Version = 1
+)
+
+// ErrInvalidArchive is returned when the archive header is missing or is not a flodk archive.
+var ErrInvalidArchive = errors.New("invalid flodk archive")
+
+// UnsupportedVersionError is returned when importing an archive written with a newer format version.
+type UnsupportedVersionError int
+
+// Error implements the error interface for the unsupported version error.
+func (uv UnsupportedVersionError) Error() string {
+	return fmt.Sprintf("unsupported archive version %d, supported up to %d", int(uv), Version)
+}
+
+// Header is the first line of an archive.
+type Header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Record is a single archived execution.
+type Record[T any] struct {
+	ID     flodk.ExecutionID       `json:"id"`
+	State  flodk.ExecutionState[T] `json:"state"`
+	Visits []flodk.Visit           `json:"visits,omitempty"`
+}
+
+// Exporter writes executions from a store into an archive.
+type Exporter[T any] struct {
+	store    flodk.Store[T]
+	history  flodk.HistoryStore
+	redactor flodk.Redactor[T]
+}
+
+// NewExporter creates a new [Exporter] reading from the passed store.
+func NewExporter[T any](store flodk.Store[T]) *Exporter[T] {
+	return &Exporter[T]{
+		store: store,
+	}
+}
+
+// WithRedactor sets the redactor applied to the application state of every exported
+// execution, see [flodk.Pipe.Redact] and [flodk.RedactFields]. The answer values and the
+// signal payloads of redacted executions are masked as well, see [flodk.RedactCheckpoint].
+func (e *Exporter[T]) WithRedactor(r flodk.Redactor[T]) *Exporter[T] {
+	e.redactor = r
+
+	return e
+}
+
+// WithHistoryStore sets the history store the full visit history of the executions is exported from.
+func (e *Exporter[T]) WithHistoryStore(hs flodk.HistoryStore) *Exporter[T] {
+	e.history = hs
+
+	return e
+}
+
+// Export writes the passed executions into an archive.
+func (e *Exporter[T]) Export(ctx context.Context, w io.Writer, ids ...flodk.ExecutionID) error {
+	enc := json.NewEncoder(w)
+
+	err := enc.Encode(Header{
+		Format:    Format,
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		state, err := e.store.Get(ctx, id)
+		if err != nil {
+			return fmt.Errorf("exporting %s:%s: %w", id.FlowName, id.ID, err)
+		}
+
+		if e.redactor != nil {
+			state.CheckpointState = flodk.RedactCheckpoint(state.CheckpointState)
+			state.ApplicationState = e.redactor(state.ApplicationState)
+		}
+
+		record := Record[T]{ID: id, State: state}
+		if e.history != nil {
+			record.Visits, err = e.history.Visits(ctx, id)
+			if err != nil {
+				return fmt.Errorf("exporting history of %s:%s: %w", id.FlowName, id.ID, err)
+			}
+		}
+
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportFlow writes all the executions of the flow into an archive. The store must
+// implement the [flodk.Lister] interface.
+func (e *Exporter[T]) ExportFlow(ctx context.Context, w io.Writer, flowName string) error {
+	lister, ok := e.store.(flodk.Lister)
+	if !ok {
+		return flodk.ErrStoreUnsupported
+	}
+
+	ids, err := lister.List(ctx, flowName)
+	if err != nil {
+		return err
+	}
+
+	return e.Export(ctx, w, ids...)
+}
+
+// Importer reads executions from an archive into a store.
+type Importer[T any] struct {
+	store   flodk.Store[T]
+	history flodk.HistoryStore
+}
+
+// NewImporter creates a new [Importer] writing into the passed store.
+func NewImporter[T any](store flodk.Store[T]) *Importer[T] {
+	return &Importer[T]{
+		store: store,
+	}
+}
+
+// WithHistoryStore sets the history store the archived visit history is imported into.
+func (im *Importer[T]) WithHistoryStore(hs flodk.HistoryStore) *Importer[T] {
+	im.history = hs
+
+	return im
+}
+
+// Import reads all the executions of the archive into the store, overwriting
+// existing executions with the same IDs. The imported execution IDs are returned.
+func Import[T any](ctx context.Context, r io.Reader, store flodk.Store[T]) ([]flodk.ExecutionID, error) {
+	return NewImporter(store).Import(ctx, r)
+}
+
+// Import reads all the executions of the archive into the store of the importer,
+// overwriting existing executions with the same IDs. The imported execution IDs are returned.
+func (im *Importer[T]) Import(ctx context.Context, r io.Reader) ([]flodk.ExecutionID, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrInvalidArchive
+	}
+
+	header := Header{}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Format != Format {
+		return nil, ErrInvalidArchive
+	}
+
+	if header.Version > Version {
+		return nil, UnsupportedVersionError(header.Version)
+	}
+
+	ids := []flodk.ExecutionID{}
+	for line := 2; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := Record[T]{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return ids, fmt.Errorf("archive line %d: %w", line, err)
+		}
+
+		if err := im.store.Set(ctx, record.ID, record.State); err != nil {
+			return ids, fmt.Errorf("importing %s:%s: %w", record.ID.FlowName, record.ID.ID, err)
+		}
+
+		if im.history != nil {
+			for _, visit := range record.Visits {
+				if err := im.history.AppendVisit(ctx, record.ID, visit); err != nil {
+					return ids, fmt.Errorf("importing history of %s:%s: %w", record.ID.FlowName, record.ID.ID, err)
+				}
+			}
+		}
+
+		ids = append(ids, record.ID)
+	}
+
+	return ids, scanner.Err()
+}
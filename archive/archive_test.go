@@ -0,0 +1,81 @@
+package archive_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aki-kong/flodk"
+	"github.com/aki-kong/flodk/archive"
+	"github.com/aki-kong/flodk/storetest"
+)
+
+func TestExportImport(t *testing.T) {
+	src := flodk.NewInMemoryStore[storetest.State]()
+	for i, id := range []string{"thread-1", "thread-2"} {
+		_ = src.Set(t.Context(), flodk.ExecutionID{ID: id, FlowName: "flow"}, storetest.Execution(i))
+	}
+
+	buf := bytes.Buffer{}
+	if err := archive.NewExporter[storetest.State](src).ExportFlow(t.Context(), &buf, "flow"); err != nil {
+		t.Fatalf("error while exporting: %s", err)
+	}
+
+	dst := flodk.NewFileStore[storetest.State](t.TempDir())
+	ids, err := archive.Import(t.Context(), &buf, dst)
+	if err != nil {
+		t.Fatalf("error while importing: %s", err)
+	}
+
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 imported executions, got %d", len(ids))
+	}
+
+	for _, id := range ids {
+		want, _ := src.Get(t.Context(), id)
+		got, err := dst.Get(t.Context(), id)
+		if err != nil {
+			t.Fatalf("error while getting %s: %s", id.ID, err)
+		}
+
+		want.Revision, got.Revision = 0, 0
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("execution %s mismatch:\nwant: %+v\n got: %+v", id.ID, want, got)
+		}
+	}
+}
+
+func TestImportVersion(t *testing.T) {
+	store := flodk.NewInMemoryStore[storetest.State]()
+
+	_, err := archive.Import(t.Context(), strings.NewReader(`{"format":"flodk-archive","version":99}`), store)
+	var versionErr archive.UnsupportedVersionError
+	if !errors.As(err, &versionErr) {
+		t.Errorf("expected UnsupportedVersionError, got %v", err)
+	}
+
+	_, err = archive.Import(t.Context(), strings.NewReader(`{"hello":"world"}`), store)
+	if !errors.Is(err, archive.ErrInvalidArchive) {
+		t.Errorf("expected ErrInvalidArchive, got %v", err)
+	}
+}
+
+func TestExportRedacted(t *testing.T) {
+	src := flodk.NewInMemoryStore[storetest.State]()
+	id := flodk.ExecutionID{ID: "thread-1", FlowName: "flow"}
+	_ = src.Set(t.Context(), id, storetest.Execution(0))
+
+	buf := bytes.Buffer{}
+	err := archive.NewExporter[storetest.State](src).
+		WithRedactor(flodk.RedactFields[storetest.State]).
+		Export(t.Context(), &buf, id)
+	if err != nil {
+		t.Fatalf("error while exporting: %s", err)
+	}
+
+	if strings.Contains(buf.String(), "Jane Doe") {
+		t.Errorf("expected the answer values to be redacted, got %s", buf.String())
+	}
+}
@@ -0,0 +1,79 @@
+package flodk
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Principal identifies the user resolving an interrupt.
+type Principal struct {
+	ID    string   `json:"id"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Authorizer decides whether a principal may resolve the pending interrupt of an execution.
+// It's consulted by [Pipe.Continue] before the interrupt values are validated.
+type Authorizer interface {
+	Authorize(ctx context.Context, id ExecutionID, interrupt HITLInterrupt, resolver Principal) error
+}
+
+// AuthorizerFunc is a function type which implements the [Authorizer] interface.
+type AuthorizerFunc func(ctx context.Context, id ExecutionID, interrupt HITLInterrupt, resolver Principal) error
+
+// Authorize implements the [Authorizer] interface for AuthorizerFunc.
+func (fn AuthorizerFunc) Authorize(ctx context.Context, id ExecutionID, interrupt HITLInterrupt, resolver Principal) error {
+	return fn(ctx, id, interrupt, resolver)
+}
+
+// UnauthorizedError is returned when a principal isn't authorized to resolve an interrupt.
+type UnauthorizedError struct {
+	Principal   string
+	InterruptID InterruptID
+	Reason      string
+}
+
+// Error implements the error interface for the unauthorized error.
+func (ue UnauthorizedError) Error() string {
+	return fmt.Sprintf("principal '%s' is not authorized to resolve interrupt '%s': %s", ue.Principal, ue.InterruptID, ue.Reason)
+}
+
+// AssignmentAuthorizer is an [Authorizer] enforcing the assignment of the interrupts. Interrupts
+// with an Assignee may only be resolved by the assignee, and interrupts with Roles may only be
+// resolved by principals having one of the roles.
+type AssignmentAuthorizer struct{}
+
+// Authorize implements the [Authorizer] interface for [AssignmentAuthorizer].
+func (AssignmentAuthorizer) Authorize(ctx context.Context, id ExecutionID, interrupt HITLInterrupt, resolver Principal) error {
+	unauthorized := func(reason string) error {
+		return UnauthorizedError{
+			Principal:   resolver.ID,
+			InterruptID: interrupt.InterruptID,
+			Reason:      reason,
+		}
+	}
+
+	if resolver.ID == "" {
+		return unauthorized("anonymous resolver")
+	}
+
+	if interrupt.Assignee != "" && interrupt.Assignee != resolver.ID {
+		return unauthorized("interrupt is assigned to '" + interrupt.Assignee + "'")
+	}
+
+	if len(interrupt.Roles) > 0 && !slices.ContainsFunc(resolver.Roles, func(role string) bool {
+		return slices.Contains(interrupt.Roles, role)
+	}) {
+		return unauthorized(fmt.Sprintf("one of the roles %v is required", interrupt.Roles))
+	}
+
+	return nil
+}
+
+// WithAuthorizer sets the authorizer consulted by [Pipe.Continue]. Without an authorizer any
+// resolver may resolve any interrupt.
+func (p *Pipe[T]) WithAuthorizer(a Authorizer) *Pipe[T] {
+	p.authorizer = a
+
+	return p
+}
@@ -0,0 +1,65 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPipeAuthorizer(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("approve", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			_, err := RequestInterrupt(ctx, HITLInterrupt{
+				Message:      "Approve the refund?",
+				Requirements: Requirements{"ok": {Type: Boolean}},
+				Assignee:     "alice",
+				Roles:        []string{"supervisor"},
+			}, func(map[string]string) error { return nil })
+			return state, err
+		})).
+		SetStartNode("approve").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("refunds", graph, store).
+		WithClock(clock).
+		WithAuthorizer(AssignmentAuthorizer{})
+
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected interrupt, got nil")
+	}
+
+	values := map[string]string{"ok": "yes"}
+	for _, resolver := range []Principal{
+		{},
+		{ID: "bob", Roles: []string{"supervisor"}},
+		{ID: "alice", Roles: []string{"agent"}},
+	} {
+		_, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: values, Resolver: resolver})
+
+		var unauthorized UnauthorizedError
+		if !errors.As(err, &unauthorized) || unauthorized.Principal != resolver.ID {
+			t.Errorf("expected UnauthorizedError for %+v, got %v", resolver, err)
+		}
+	}
+
+	alice := Principal{ID: "alice", Roles: []string{"agent", "supervisor"}}
+	if _, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: values, Resolver: alice}); err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "refunds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := es.CheckpointState.InterruptHistory
+	if len(history) != 1 || history[0].ResolvedBy.ID != "alice" || !history[0].ResolvedAt.Equal(clock.Now()) {
+		t.Errorf("unexpected interrupt history: %+v", history)
+	}
+}
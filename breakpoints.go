@@ -0,0 +1,94 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// BreakpointBefore is the reason of the interrupts raised before executing a node.
+	BreakpointBefore = "breakpoint_before"
+	// BreakpointAfter is the reason of the interrupts raised after executing a node.
+	BreakpointAfter = "breakpoint_after"
+
+	// breakpointKey is the interrupt key of breakpoints.
+	breakpointKey = "breakpoint"
+)
+
+// ErrStatePatchUnsupported is returned when continuing an execution with a state patch
+// while it isn't paused on a breakpoint.
+var ErrStatePatchUnsupported = errors.New("state patches are only supported on breakpoints")
+
+// Breakpoints are the nodes the execution pauses before or after, without changing the node
+// code. Hitting a breakpoint persists the execution like a [HITLInterrupt] with the reason
+// [BreakpointBefore] or [BreakpointAfter], and [Pipe.Continue] resumes the execution from
+// exactly that spot, optionally editing the state (see [ResumeConfig.StatePatch]).
+// Breakpoints after a terminal node are ignored.
+type Breakpoints struct {
+	Before []string
+	After  []string
+}
+
+// merge returns the union of the breakpoints.
+func (b Breakpoints) merge(other Breakpoints) Breakpoints {
+	return Breakpoints{
+		Before: append(slices.Clip(b.Before), other.Before...),
+		After:  append(slices.Clip(b.After), other.After...),
+	}
+}
+
+// breakpointsKey is the context key of the per invocation [Breakpoints].
+type breakpointsKey struct{}
+
+// LoadBreakpoints loads the context with breakpoints applied to the pipe invocations using the
+// context, in addition to the breakpoints of the pipe (see [Pipe.WithBreakpoints]).
+func LoadBreakpoints(ctx context.Context, bp Breakpoints) context.Context {
+	return context.WithValue(ctx, breakpointsKey{}, getBreakpoints(ctx).merge(bp))
+}
+
+// getBreakpoints returns the breakpoints loaded in the context.
+func getBreakpoints(ctx context.Context) Breakpoints {
+	bp, _ := ctx.Value(breakpointsKey{}).(Breakpoints)
+	return bp
+}
+
+// WithBreakpoints sets the breakpoints of all the executions of the pipe.
+func (p *Pipe[T]) WithBreakpoints(bp Breakpoints) *Pipe[T] {
+	p.breakpoints = bp
+
+	return p
+}
+
+// WithBreakpoints sets the breakpoints of the flow execution.
+func (f *Flow[T]) WithBreakpoints(bp Breakpoints) *Flow[T] {
+	f.breakpoints = bp
+
+	return f
+}
+
+// breakpoint returns the interrupt of a breakpoint hit at the node. The execution is resumed on
+// the node the interrupt is raised for.
+func breakpoint(ctx context.Context, reason string, nodeID string, resumeAt string) HITLInterrupt {
+	where := "before"
+	if reason == BreakpointAfter {
+		where = "after"
+	}
+
+	return HITLInterrupt{
+		Reason:       reason,
+		Message:      fmt.Sprintf("breakpoint %s node '%s'", where, nodeID),
+		Requirements: Requirements{},
+		InterruptID: InterruptID{
+			NodeID: resumeAt,
+			ID:     GetIDGenerator(ctx).NewID(),
+			Key:    breakpointKey,
+		},
+	}
+}
+
+// isBreakpoint returns true if the interrupt was raised by a breakpoint.
+func (it HITLInterrupt) isBreakpoint() bool {
+	return it.InterruptID.Key == breakpointKey
+}
@@ -0,0 +1,185 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type counter struct {
+	N     int      `json:"n"`
+	Trace []string `json:"trace"`
+}
+
+func traceNode(name string) Node[counter] {
+	return FunctionNode[counter](func(ctx context.Context, state counter) (counter, error) {
+		state.N++
+		state.Trace = append(state.Trace, name)
+		return state, nil
+	})
+}
+
+func TestPipeBreakpoints(t *testing.T) {
+	graph, err := NewGraphBuilder[counter]().
+		AddNode("a", traceNode("a")).
+		AddNode("b", traceNode("b")).
+		AddNode("c", traceNode("c")).
+		AddEdge("a", "b").
+		AddEdge("b", "c").
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[counter]()
+	pipe := NewPipe("breakpoints", graph, store).WithBreakpoints(Breakpoints{Before: []string{"b"}})
+
+	var interrupt HITLInterrupt
+	state, err := pipe.Invoke(t.Context(), "thread-1", counter{})
+	if !errors.As(err, &interrupt) || interrupt.Reason != BreakpointBefore || interrupt.InterruptID.NodeID != "b" {
+		t.Fatalf("expected breakpoint before b, got %v", err)
+	}
+
+	if state.N != 1 {
+		t.Errorf("expected only a to be executed, got %+v", state)
+	}
+
+	// Pause after b for this invocation only, editing the state on resumption.
+	ctx := LoadBreakpoints(t.Context(), Breakpoints{After: []string{"b"}})
+	state, err = pipe.Continue(ctx, "thread-1", ResumeConfig{StatePatch: json.RawMessage(`{"n": 10}`)})
+	if !errors.As(err, &interrupt) || interrupt.Reason != BreakpointAfter || interrupt.InterruptID.NodeID != "c" {
+		t.Fatalf("expected breakpoint after b, got %v", err)
+	}
+
+	if state.N != 11 {
+		t.Errorf("expected the patched state to be resumed, got %+v", state)
+	}
+
+	state, err = pipe.Continue(t.Context(), "thread-1", ResumeConfig{})
+	if err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	if want := []string{"a", "b", "c"}; state.N != 12 || len(state.Trace) != len(want) {
+		t.Errorf("expected each node to run once, got %+v", state)
+	}
+
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "breakpoints"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if visits := es.CheckpointState.Visited.Len(); visits != 3 {
+		t.Errorf("expected 3 visits, got %d", visits)
+	}
+}
+
+func TestPipeStatePatchUnsupported(t *testing.T) {
+	graph, err := NewGraphBuilder[counter]().
+		AddNode("ask", FunctionNode[counter](func(ctx context.Context, state counter) (counter, error) {
+			_, err := Interrupt(ctx, "Continue?", "confirm", Requirements{})
+			return state, err
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("patches", graph, NewInMemoryStore[counter]())
+	if _, err := pipe.Invoke(t.Context(), "thread-1", counter{}); err == nil {
+		t.Fatal("expected interrupt, got nil")
+	}
+
+	if _, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{StatePatch: json.RawMessage(`{"n": 1}`)}); !errors.Is(err, ErrStatePatchUnsupported) {
+		t.Errorf("expected ErrStatePatchUnsupported, got %v", err)
+	}
+}
+
+func TestPipeBreakpointAfterThenBefore(t *testing.T) {
+	graph, err := NewGraphBuilder[counter]().
+		AddNode("a", traceNode("a")).
+		AddNode("b", traceNode("b")).
+		AddEdge("a", "b").
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("breakpoints", graph, NewInMemoryStore[counter]()).
+		WithBreakpoints(Breakpoints{Before: []string{"b"}, After: []string{"a"}})
+
+	var interrupt HITLInterrupt
+	if _, err := pipe.Invoke(t.Context(), "thread-1", counter{}); !errors.As(err, &interrupt) || interrupt.Reason != BreakpointAfter {
+		t.Fatalf("expected breakpoint after a, got %v", err)
+	}
+
+	state, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{})
+	if !errors.As(err, &interrupt) || interrupt.Reason != BreakpointBefore || interrupt.InterruptID.NodeID != "b" {
+		t.Fatalf("expected breakpoint before b, got %v", err)
+	}
+
+	if state.N != 1 {
+		t.Errorf("expected b not to be executed yet, got %+v", state)
+	}
+
+	state, err = pipe.Continue(t.Context(), "thread-1", ResumeConfig{})
+	if err != nil || state.N != 2 {
+		t.Errorf("expected the flow to complete, got %+v with %v", state, err)
+	}
+}
+
+func TestPipeStatePatchPointerState(t *testing.T) {
+	fail := true
+	graph, err := NewGraphBuilder[*counter]().
+		AddNode("a", FunctionNode[*counter](func(ctx context.Context, state *counter) (*counter, error) {
+			return &counter{N: state.N + 1}, nil
+		})).
+		AddNode("b", FunctionNode[*counter](func(ctx context.Context, state *counter) (*counter, error) {
+			if fail {
+				return state, errors.New("node failed")
+			}
+
+			return &counter{N: state.N + 1}, nil
+		})).
+		AddEdge("a", "b").
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[*counter]()
+	pipe := NewPipe("patches", graph, store).WithBreakpoints(Breakpoints{Before: []string{"b"}})
+	if _, err := pipe.Invoke(t.Context(), "thread-1", &counter{}); err == nil {
+		t.Fatal("expected breakpoint before b, got nil")
+	}
+
+	rc := ResumeConfig{StatePatch: json.RawMessage(`{"n": 10}`)}
+	if _, err := pipe.Continue(t.Context(), "thread-1", rc); err == nil {
+		t.Fatal("expected the node error, got nil")
+	}
+
+	// The persisted state isn't patched in place.
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "patches"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if es.ApplicationState.N != 1 || es.CheckpointState.Status != StatusInterrupted {
+		t.Errorf("expected the unpatched state at the breakpoint, got %+v with %+v", es.ApplicationState, es.CheckpointState)
+	}
+
+	fail = false
+	state, err := pipe.Continue(t.Context(), "thread-1", rc)
+	if err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	if state.N != 11 {
+		t.Errorf("expected the patched state to be resumed, got %+v", state)
+	}
+}
@@ -0,0 +1,160 @@
+package flodk
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time to the flow executions. Inject a deterministic clock
+// like [ManualClock] using [Pipe.WithClock] for tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator generates the unique IDs of the interrupts. Inject a deterministic generator
+// like [SequentialIDs] using [Pipe.WithIDGenerator] for tests.
+type IDGenerator interface {
+	NewID() string
+}
+
+// SystemClock is the default [Clock] which returns the system time.
+type SystemClock struct{}
+
+// Now implements the [Clock] interface for [SystemClock].
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// RandomIDs is the default [IDGenerator] which generates IDs from the system time
+// and a random number.
+type RandomIDs struct{}
+
+// NewID implements the [IDGenerator] interface for [RandomIDs].
+func (RandomIDs) NewID() string {
+	return fmt.Sprintf("%x.%x", time.Now().UnixNano(), rand.Int64())
+}
+
+// ManualClock is a deterministic [Clock] which only moves when it's set or advanced.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a new [ManualClock] set to the passed time.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{
+		now: now,
+	}
+}
+
+// Now implements the [Clock] interface for [ManualClock].
+func (mc *ManualClock) Now() time.Time {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.now
+}
+
+// Set sets the clock to the passed time.
+func (mc *ManualClock) Set(now time.Time) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.now = now
+}
+
+// Advance moves the clock forward by the passed duration.
+func (mc *ManualClock) Advance(d time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.now = mc.now.Add(d)
+}
+
+// SequentialIDs is a deterministic [IDGenerator] generating the IDs `<prefix>1`, `<prefix>2`, ...
+type SequentialIDs struct {
+	mu     sync.Mutex
+	prefix string
+	n      int
+}
+
+// NewSequentialIDs creates a new [SequentialIDs] generator.
+func NewSequentialIDs(prefix string) *SequentialIDs {
+	return &SequentialIDs{
+		prefix: prefix,
+	}
+}
+
+// NewID implements the [IDGenerator] interface for [SequentialIDs].
+func (s *SequentialIDs) NewID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.n++
+	return fmt.Sprintf("%s%d", s.prefix, s.n)
+}
+
+// clockKey is the context key of the [Clock].
+type clockKey struct{}
+
+// idGeneratorKey is the context key of the [IDGenerator].
+type idGeneratorKey struct{}
+
+// LoadClock is used to store the clock into the passed context.
+func LoadClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, clock)
+}
+
+// GetClock is used to retrieve the clock from the context. [SystemClock] is
+// returned when no clock is loaded.
+func GetClock(ctx context.Context) Clock {
+	clock, ok := ctx.Value(clockKey{}).(Clock)
+	if !ok {
+		return SystemClock{}
+	}
+
+	return clock
+}
+
+// LoadIDGenerator is used to store the ID generator into the passed context.
+func LoadIDGenerator(ctx context.Context, gen IDGenerator) context.Context {
+	return context.WithValue(ctx, idGeneratorKey{}, gen)
+}
+
+// GetIDGenerator is used to retrieve the ID generator from the context. [RandomIDs]
+// is returned when no ID generator is loaded.
+func GetIDGenerator(ctx context.Context) IDGenerator {
+	gen, ok := ctx.Value(idGeneratorKey{}).(IDGenerator)
+	if !ok {
+		return RandomIDs{}
+	}
+
+	return gen
+}
+
+// WithClock sets the clock used for the timestamps recorded in the checkpoints,
+// the retention policy and the nodes of the flow.
+func (p *Pipe[T]) WithClock(clock Clock) *Pipe[T] {
+	p.clock = clock
+
+	return p
+}
+
+// WithIDGenerator sets the ID generator used for the interrupt IDs.
+func (p *Pipe[T]) WithIDGenerator(gen IDGenerator) *Pipe[T] {
+	p.idGen = gen
+
+	return p
+}
+
+// now returns the current time used for the execution timestamps.
+func (p *Pipe[T]) now() time.Time {
+	if p.clock == nil {
+		return time.Now()
+	}
+
+	return p.clock.Now()
+}
@@ -0,0 +1,53 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// askNodeFor returns a node which interrupts once for an `ok` answer.
+func askNodeFor[T any]() Node[T] {
+	return FunctionNode[T](func(ctx context.Context, state T) (T, error) {
+		_, err := Interrupt(ctx, "Continue?", "confirm", Requirements{"ok": {Type: Custom}})
+		return state, err
+	})
+}
+
+func TestPipeDeterministicClockAndIDs(t *testing.T) {
+	graph, err := NewGraphBuilder[passengerState]().
+		AddNode("greet", askNodeFor[passengerState]()).
+		SetStartNode("greet").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := NewManualClock(start)
+	store := NewInMemoryStore[passengerState]()
+	pipe := NewPipe("clock", graph, store).
+		WithClock(clock).
+		WithIDGenerator(NewSequentialIDs("interrupt-"))
+
+	_, err = pipe.Invoke(t.Context(), "thread-1", passengerState{})
+	var interrupt HITLInterrupt
+	if !errors.As(err, &interrupt) {
+		t.Fatalf("expected interrupt, got %v", err)
+	}
+
+	if interrupt.InterruptID != (InterruptID{NodeID: "greet", ID: "interrupt-1"}) {
+		t.Errorf("unexpected interrupt ID: %+v", interrupt.InterruptID)
+	}
+
+	clock.Advance(time.Hour)
+	if _, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"ok": "yes"}}); err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	state, _ := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "clock"})
+	if !state.CheckpointState.CreatedAt.Equal(start) || !state.CheckpointState.UpdatedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("unexpected timestamps: created %s, updated %s", state.CheckpointState.CreatedAt, state.CheckpointState.UpdatedAt)
+	}
+}
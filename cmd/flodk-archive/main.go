@@ -0,0 +1,141 @@
+// Command flodk-archive exports and imports flodk executions persisted in a
+// [flodk.FileStore] directory as portable archives.
+//
+// Usage:
+//
+//	flodk-archive export -dir ./data -flow book_flights [-id thread-123 ...] [-o archive.jsonl]
+//	flodk-archive import -dir ./data [-i archive.jsonl]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aki-kong/flodk"
+	"github.com/aki-kong/flodk/archive"
+)
+
+// ids collects the repeated -id flags.
+type ids []string
+
+func (i *ids) String() string {
+	return strings.Join(*i, ",")
+}
+
+func (i *ids) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  flodk-archive export -dir DIR -flow FLOW [-id ID ...] [-o FILE]")
+	fmt.Fprintln(os.Stderr, "  flodk-archive import -dir DIR [-i FILE]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = export(os.Args[2:])
+	case "import":
+		err = importArchive(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "flodk-archive: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// export writes the selected executions of a flow into an archive.
+func export(args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := fs.String("dir", "", "file store directory")
+	flow := fs.String("flow", "", "flow name of the executions")
+	out := fs.String("o", "", "archive file to write, defaults to stdout")
+	selected := ids{}
+	fs.Var(&selected, "id", "execution ID to export, repeatable, defaults to all the executions of the flow")
+	// Invalid flags exit the command.
+	_ = fs.Parse(args)
+
+	if *dir == "" || *flow == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+
+		// The archive is only complete once the file is closed.
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+
+		w = f
+	}
+
+	ctx := context.Background()
+	exporter := archive.NewExporter[json.RawMessage](flodk.NewFileStore[json.RawMessage](*dir))
+
+	if len(selected) == 0 {
+		return exporter.ExportFlow(ctx, w, *flow)
+	}
+
+	executions := make([]flodk.ExecutionID, 0, len(selected))
+	for _, id := range selected {
+		executions = append(executions, flodk.ExecutionID{ID: id, FlowName: *flow})
+	}
+
+	return exporter.Export(ctx, w, executions...)
+}
+
+// importArchive reads all the executions of an archive into the file store.
+func importArchive(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dir := fs.String("dir", "", "file store directory")
+	in := fs.String("i", "", "archive file to read, defaults to stdin")
+	// Invalid flags exit the command.
+	_ = fs.Parse(args)
+
+	if *dir == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var r io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	imported, err := archive.Import(context.Background(), r, flodk.NewFileStore[json.RawMessage](*dir))
+	for _, id := range imported {
+		fmt.Fprintf(os.Stderr, "imported %s:%s\n", id.FlowName, id.ID)
+	}
+
+	return err
+}
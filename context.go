@@ -0,0 +1,76 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNodeIDNotFound is returned by the functions suspending a node, e.g. [RequestInterrupt], when
+// they're called with a context which wasn't initialized by a [Flow] executing the node.
+var ErrNodeIDNotFound = errors.New("nodeID not found in context")
+
+// nodeIDKey is the context key of the current graph node id.
+type nodeIDKey struct{}
+
+// runContextKey is the context key of the [RunContext].
+type runContextKey struct{}
+
+// interruptKey is the context key of a resolved interrupt of a graph node.
+type interruptKey struct {
+	nodeID string
+	key    string
+}
+
+// RunContext describes the node execution a node is running for. Nodes executed by
+// a [Flow] can retrieve it using [GetRunContext].
+type RunContext struct {
+	// ExecutionID identifies the execution (thread) the node is running for.
+	ExecutionID ExecutionID
+	// NodeID is the ID of the executing node.
+	NodeID string
+	// Step is the number of nodes visited in the execution, including this node.
+	Step int
+	// Attempt is the number of times the node was executed for this step. It's
+	// greater than 1 when the node is resumed after an interrupt.
+	Attempt int
+	// GraphVersion is the version of the graph the execution was started with.
+	GraphVersion string
+	// Interrupt is the resolved interrupt of the node when the node is resumed.
+	Interrupt *ResolvedHITLInterrupt
+}
+
+// GetRunContext is used to retrieve the [RunContext] of the executing node from the context.
+func GetRunContext(ctx context.Context) (RunContext, bool) {
+	rc, ok := ctx.Value(runContextKey{}).(RunContext)
+	return rc, ok
+}
+
+// LoadNodeID is used to store the current graph node id (node name) into the passed context.
+func LoadNodeID(ctx context.Context, nodeID string) context.Context {
+	return context.WithValue(ctx, nodeIDKey{}, nodeID)
+}
+
+// GetNodeID is used to retrieve the current node id (node name) from the context.
+func GetNodeID(ctx context.Context) (string, bool) {
+	val, ok := ctx.Value(nodeIDKey{}).(string)
+	return val, ok
+}
+
+// LoadInterrupt is used to load the context with a resolved interrupt (original HITLInterrupt and answer values).
+func LoadInterrupt(ctx context.Context, interrupt HITLInterrupt, values map[string]string) context.Context {
+	return loadResolvedInterrupt(ctx, ResolvedHITLInterrupt{
+		HITLInterrupt: interrupt,
+		Values:        values,
+	})
+}
+
+// loadResolvedInterrupt loads the context with the resolved interrupt, keyed by its node ID and key.
+func loadResolvedInterrupt(ctx context.Context, ri ResolvedHITLInterrupt) context.Context {
+	return context.WithValue(ctx, interruptKey{nodeID: ri.InterruptID.NodeID, key: ri.InterruptID.Key}, ri)
+}
+
+// getLoadedInterrupt is a private function used to just get the loaded resolved interrupt of a node from the context.
+func getLoadedInterrupt(ctx context.Context, nodeID string, key string) (ResolvedHITLInterrupt, bool) {
+	rint, ok := ctx.Value(interruptKey{nodeID: nodeID, key: key}).(ResolvedHITLInterrupt)
+	return rint, ok
+}
@@ -0,0 +1,72 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunContext(t *testing.T) {
+	seen := []RunContext{}
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			rc, ok := GetRunContext(ctx)
+			if !ok {
+				t.Fatal("expected run context in the node context")
+			}
+			seen = append(seen, rc)
+
+			_, err := Interrupt(ctx, "Continue?", "confirm", Requirements{"ok": {Type: Custom}})
+			return state, err
+		})).
+		AddEdge("a", "ask").
+		SetStartNode("a").
+		SetVersion("v1").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("run_context", graph, NewInMemoryStore[State]())
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected interrupt, got nil")
+	}
+
+	if _, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"ok": "yes"}}); err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("expected 2 executions of the node, got %d", len(seen))
+	}
+
+	first, resumed := seen[0], seen[1]
+	want := ExecutionID{ID: "thread-1", FlowName: "run_context"}
+	if first.ExecutionID != want || first.NodeID != "ask" || first.GraphVersion != "v1" {
+		t.Errorf("unexpected run context: %+v", first)
+	}
+
+	if first.Step != 2 || first.Attempt != 1 || first.Interrupt != nil {
+		t.Errorf("unexpected first attempt: %+v", first)
+	}
+
+	if resumed.Step != 2 || resumed.Attempt != 2 || resumed.Interrupt == nil || resumed.Interrupt.Values["ok"] != "yes" {
+		t.Errorf("unexpected resumed attempt: %+v", resumed)
+	}
+}
+
+func TestNodeIDNotFound(t *testing.T) {
+	if _, err := Interrupt(t.Context(), "Continue?", "confirm", Requirements{}); !errors.Is(err, ErrNodeIDNotFound) {
+		t.Errorf("interrupt: expected ErrNodeIDNotFound, got %v", err)
+	}
+
+	if _, err := WaitForSignal[string](t.Context(), "payment"); !errors.Is(err, ErrNodeIDNotFound) {
+		t.Errorf("signal: expected ErrNodeIDNotFound, got %v", err)
+	}
+
+	if err := SleepUntil(t.Context(), "reminder", time.Now()); !errors.Is(err, ErrNodeIDNotFound) {
+		t.Errorf("timer: expected ErrNodeIDNotFound, got %v", err)
+	}
+}
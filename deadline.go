@@ -0,0 +1,222 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrExecutionFailed is returned when resuming an execution which was failed by the
+	// [TimeoutPolicy] of an expired interrupt.
+	ErrExecutionFailed = errors.New("execution failed")
+	// ErrInterruptExpired is returned when continuing an execution whose interrupt expired,
+	// but wasn't processed by [Pipe.ProcessExpired] yet.
+	ErrInterruptExpired = errors.New("interrupt expired")
+)
+
+// TimeoutAction is the action taken when an interrupt expires.
+type TimeoutAction string
+
+const (
+	// TimeoutFail marks the execution as failed. It's the default action.
+	TimeoutFail TimeoutAction = "fail"
+	// TimeoutResume resumes the execution with the default values of the requirements.
+	// The execution fails when the requirements can't be satisfied by their defaults.
+	TimeoutResume TimeoutAction = "resume"
+	// TimeoutRoute resumes the execution on the fallback node instead of the interrupted node.
+	TimeoutRoute TimeoutAction = "route"
+)
+
+// TimeoutPolicy defines when an interrupt expires and how the expired interrupt is resolved.
+type TimeoutPolicy struct {
+	// After is the duration after which the interrupt expires, counted from the time it's raised.
+	After time.Duration `json:"after,omitempty"`
+	// Action is the action taken when the interrupt expires.
+	Action TimeoutAction `json:"action,omitempty"`
+	// Fallback is the node the execution is resumed on by the [TimeoutRoute] action.
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// expired reports whether the interrupt expired at the passed time.
+func (it HITLInterrupt) expired(now time.Time) bool {
+	return !it.ExpiresAt.IsZero() && !now.Before(it.ExpiresAt)
+}
+
+// checkFallback returns a [NodeNotFoundError] when the fallback node of a [TimeoutRoute]
+// policy isn't a node of the graph.
+func checkFallback[T any](graph Graph[T], policy TimeoutPolicy) error {
+	if policy.Action != TimeoutRoute {
+		return nil
+	}
+
+	if _, ok := graph.nodeMap[policy.Fallback]; !ok {
+		return NodeNotFoundError{
+			NodeID:       policy.Fallback,
+			GraphVersion: graph.version,
+		}
+	}
+
+	return nil
+}
+
+// ExpiryReport summarizes the expired interrupts processed by [Pipe.ProcessExpired].
+type ExpiryReport struct {
+	Resumed int
+	Routed  int
+	Failed  int
+}
+
+// Add adds the counts of the passed report to this report.
+func (er *ExpiryReport) Add(other ExpiryReport) {
+	er.Resumed += other.Resumed
+	er.Routed += other.Routed
+	er.Failed += other.Failed
+}
+
+// ProcessExpired resolves the expired interrupts of the executions of the pipe using their
+// [TimeoutPolicy]. The automatic resolutions are recorded in the interrupt history with
+// [ResolutionTimeout]. All the expired executions are processed even when resuming one of
+// them fails, and the errors are joined. The store must implement the [Lister] interface. When it
+// also implements [CompareAndSwapper], executions resolved concurrently, e.g. by another scheduler,
+// are skipped, and the interrupts of resumed nodes failing with an error are pending again.
+func (p *Pipe[T]) ProcessExpired(ctx context.Context) (ExpiryReport, error) {
+	report := ExpiryReport{}
+	errs := []error{}
+	now := p.now()
+
+	err := p.forEachExecution(ctx, func(id ExecutionID, state ExecutionState[T]) error {
+		cs := state.CheckpointState
+		if cs.Status != StatusInterrupted || !cs.Interrupt.expired(now) {
+			return nil
+		}
+
+		if err := p.expire(ctx, id, state, &report); err != nil {
+			errs = append(errs, fmt.Errorf("execution %s: %w", id.ID, err))
+		}
+
+		return nil
+	})
+
+	return report, errors.Join(append(errs, err)...)
+}
+
+// expire resolves the expired interrupt of the execution.
+func (p *Pipe[T]) expire(ctx context.Context, id ExecutionID, state ExecutionState[T], report *ExpiryReport) error {
+	cs := state.CheckpointState
+	ri := ResolvedHITLInterrupt{
+		HITLInterrupt: cs.Interrupt,
+		Step:          cs.Visited.Len(),
+		Resolution:    ResolutionTimeout,
+		ResolvedAt:    p.now(),
+	}
+
+	graph, err := p.graphFor(cs)
+	if err != nil {
+		return err
+	}
+
+	// Executions resolved concurrently, e.g. by another scheduler, are skipped.
+	failed := func(state ExecutionState[T]) error {
+		err := p.fail(ctx, id, state, ri)
+		if errors.Is(err, ErrRevisionConflict) {
+			return nil
+		}
+
+		if err == nil {
+			report.Failed++
+		}
+
+		return err
+	}
+
+	switch cs.Interrupt.Timeout.Action {
+	case TimeoutResume:
+		values, err := cs.Interrupt.Requirements.Resolve(nil)
+		if err != nil {
+			return failed(state)
+		}
+
+		ri.Values = values
+		release, err := p.claim(ctx, id.ID, state, resolvedState(state, ri))
+		if errors.Is(err, ErrRevisionConflict) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = p.invoke(loadResolvedInterrupt(ctx, ri), id.ID, graph, cs, state.ApplicationState)
+
+		var interrupt HITLInterrupt
+		if errors.As(err, &interrupt) && interrupt.InterruptID == cs.Interrupt.InterruptID {
+			// The node rejected the default values and raised the interrupt again.
+			current, err := p.load(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			if current.CheckpointState.Interrupt.InterruptID != interrupt.InterruptID {
+				return nil
+			}
+
+			state.Revision = current.Revision
+			return failed(state)
+		}
+
+		if err := release(err); ignoreSuspension(err) != nil {
+			return err
+		}
+
+		report.Resumed++
+		return nil
+	case TimeoutRoute:
+		if err := checkFallback(graph, ri.Timeout); err != nil {
+			return err
+		}
+
+		routed := resolvedState(state, ri)
+		routed.CheckpointState.CheckpointID = ri.Timeout.Fallback
+		release, err := p.claim(ctx, id.ID, state, routed)
+		if errors.Is(err, ErrRevisionConflict) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = p.invoke(ctx, id.ID, graph, routed.CheckpointState, state.ApplicationState)
+		if err := release(err); ignoreSuspension(err) != nil {
+			return err
+		}
+
+		report.Routed++
+		return nil
+	default:
+		return failed(state)
+	}
+}
+
+// fail records the resolved interrupt and marks the execution as failed. [ErrRevisionConflict]
+// is returned when the execution was persisted since it was loaded, see [Pipe.update].
+func (p *Pipe[T]) fail(ctx context.Context, id ExecutionID, state ExecutionState[T], ri ResolvedHITLInterrupt) error {
+	state = resolvedState(state, ri)
+	state.CheckpointState.Status = StatusFailed
+	state.CheckpointState.UpdatedAt = p.now()
+
+	return p.update(ctx, id, state)
+}
+
+// ignoreSuspension returns nil for the errors which only report that the execution
+// was suspended again, e.g. waiting on another interrupt.
+func ignoreSuspension(err error) error {
+	var suspended suspension
+	if errors.As(err, &suspended) {
+		return nil
+	}
+
+	return err
+}
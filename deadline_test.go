@@ -0,0 +1,345 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPipeProcessExpired(t *testing.T) {
+	timeouts := map[string]TimeoutPolicy{
+		"resume":  {After: time.Hour, Action: TimeoutResume},
+		"route":   {After: time.Hour, Action: TimeoutRoute, Fallback: "fallback"},
+		"fail":    {After: time.Hour},
+		"pending": {After: 3 * time.Hour, Action: TimeoutResume},
+	}
+
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			rc, _ := GetRunContext(ctx)
+			values, err := RequestInterrupt(ctx, HITLInterrupt{
+				Message:      "How many?",
+				Requirements: Requirements{"count": {Type: Integer, Default: "5"}},
+				Timeout:      timeouts[rc.ExecutionID.ID],
+			}, func(map[string]string) error { return nil })
+			if err != nil {
+				return state, err
+			}
+
+			count, err := Values(values).Int("count")
+			state.sum += int(count)
+			return state, err
+		})).
+		AddNode("fallback", AdderNode(100)).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("deadlines", graph, store).WithClock(clock)
+
+	for id := range timeouts {
+		var interrupt HITLInterrupt
+		if _, err := pipe.Invoke(t.Context(), id, State{}); !errors.As(err, &interrupt) {
+			t.Fatalf("expected interrupt, got %v", err)
+		}
+
+		if want := clock.Now().Add(timeouts[id].After); !interrupt.ExpiresAt.Equal(want) {
+			t.Errorf("expected expiry at %s, got %s", want, interrupt.ExpiresAt)
+		}
+	}
+
+	clock.Advance(2 * time.Hour)
+
+	report, err := pipe.ProcessExpired(t.Context())
+	if err != nil {
+		t.Fatalf("error while processing expired interrupts: %s", err)
+	}
+
+	if report != (ExpiryReport{Resumed: 1, Routed: 1, Failed: 1}) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	want := map[string]struct {
+		status ExecutionStatus
+		sum    int
+	}{
+		"resume":  {StatusCompleted, 5},
+		"route":   {StatusCompleted, 100},
+		"fail":    {StatusFailed, 0},
+		"pending": {StatusInterrupted, 0},
+	}
+
+	for id, w := range want {
+		es, err := store.Get(t.Context(), ExecutionID{ID: id, FlowName: "deadlines"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cs := es.CheckpointState
+		if cs.Status != w.status || es.ApplicationState.sum != w.sum {
+			t.Errorf("%s: expected %s with sum %d, got %s with sum %d", id, w.status, w.sum, cs.Status, es.ApplicationState.sum)
+		}
+
+		if id != "pending" && (len(cs.InterruptHistory) != 1 || cs.InterruptHistory[0].Resolution != ResolutionTimeout) {
+			t.Errorf("%s: expected timeout resolution in history, got %+v", id, cs.InterruptHistory)
+		}
+	}
+
+	if _, err := pipe.Continue(t.Context(), "fail", ResumeConfig{}); !errors.Is(err, ErrExecutionFailed) {
+		t.Errorf("expected ErrExecutionFailed, got %v", err)
+	}
+
+	// Expired interrupts can't be answered before they're processed.
+	clock.Advance(2 * time.Hour)
+	_, err = pipe.Continue(t.Context(), "pending", ResumeConfig{InterruptValues: map[string]string{"count": "1"}})
+	if !errors.Is(err, ErrInterruptExpired) {
+		t.Errorf("expected ErrInterruptExpired, got %v", err)
+	}
+}
+
+func TestTimeoutRouteMissingFallback(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			_, err := RequestInterrupt(ctx, HITLInterrupt{
+				Message:      "Continue?",
+				Requirements: Requirements{"ok": {Type: Custom}},
+				Timeout:      TimeoutPolicy{After: time.Hour, Action: TimeoutRoute, Fallback: "missing"},
+			}, func(map[string]string) error { return nil })
+			return state, err
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("deadlines", graph, store).WithClock(clock)
+
+	var notFound NodeNotFoundError
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); !errors.As(err, &notFound) || notFound.NodeID != "missing" {
+		t.Errorf("expected NodeNotFoundError for the fallback, got %v", err)
+	}
+
+	// Executions persisted before the fallback node was removed.
+	_ = store.Set(t.Context(), ExecutionID{ID: "thread-2", FlowName: "deadlines"}, ExecutionState[State]{
+		CheckpointState: CheckpointState{
+			CheckpointID: "ask",
+			Status:       StatusInterrupted,
+			Interrupt: HITLInterrupt{
+				InterruptID: InterruptID{NodeID: "ask", ID: "1"},
+				ExpiresAt:   clock.Now(),
+				Timeout:     TimeoutPolicy{Action: TimeoutRoute, Fallback: "missing"},
+			},
+		},
+	})
+
+	report, err := pipe.ProcessExpired(t.Context())
+	if !errors.As(err, &notFound) || notFound.NodeID != "missing" {
+		t.Errorf("expected NodeNotFoundError for the fallback, got %v", err)
+	}
+
+	if report != (ExpiryReport{}) {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestProcessExpiredSkipsUnloadable(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", askNodeFor[State]()).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewInMemoryStore[State]()
+	for id, version := range map[string]int{"broken": 5, "expired": 0} {
+		_ = store.Set(t.Context(), ExecutionID{ID: id, FlowName: "deadlines"}, ExecutionState[State]{
+			CheckpointState: CheckpointState{
+				CheckpointID: "ask",
+				Status:       StatusInterrupted,
+				Interrupt:    HITLInterrupt{InterruptID: InterruptID{NodeID: "ask", ID: "1"}, ExpiresAt: now},
+			},
+			SchemaVersion: version,
+		})
+	}
+
+	report, err := NewPipe("deadlines", graph, store).
+		WithClock(NewManualClock(now)).
+		ProcessExpired(t.Context())
+	if !errors.Is(err, ErrSchemaVersionUnsupported) {
+		t.Errorf("expected the load error of the broken execution, got %v", err)
+	}
+
+	if report.Failed != 1 {
+		t.Errorf("expected the expired execution to be processed, got %+v", report)
+	}
+}
+
+func TestPipeProcessExpiredConcurrently(t *testing.T) {
+	timeouts := map[string]TimeoutPolicy{
+		"resume": {After: time.Hour, Action: TimeoutResume},
+		"route":  {After: time.Hour, Action: TimeoutRoute, Fallback: "fallback"},
+		"fail":   {After: time.Hour},
+	}
+
+	executed := map[string]int{}
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			rc, _ := GetRunContext(ctx)
+			if _, err := RequestInterrupt(ctx, HITLInterrupt{
+				Message:      "Continue?",
+				Requirements: Requirements{"ok": {Type: Boolean, Default: "true"}},
+				Timeout:      timeouts[rc.ExecutionID.ID],
+			}, func(map[string]string) error { return nil }); err != nil {
+				return state, err
+			}
+
+			executed[rc.ExecutionID.ID]++
+			return state, nil
+		})).
+		AddNode("fallback", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			rc, _ := GetRunContext(ctx)
+			executed[rc.ExecutionID.ID]++
+			return state, nil
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := &racingStore[State]{InMemoryStore: NewInMemoryStore[State]()}
+	pipe := NewPipe("deadlines", graph, store).WithClock(clock)
+
+	for id := range timeouts {
+		if _, err := pipe.Invoke(t.Context(), id, State{}); err == nil {
+			t.Fatalf("%s: expected the flow to be interrupted", id)
+		}
+	}
+
+	clock.Advance(2 * time.Hour)
+
+	var concurrent ExpiryReport
+	store.race = func() {
+		var err error
+		if concurrent, err = pipe.ProcessExpired(t.Context()); err != nil {
+			t.Errorf("error while processing expired interrupts concurrently: %s", err)
+		}
+	}
+
+	report, err := pipe.ProcessExpired(t.Context())
+	if err != nil {
+		t.Fatalf("error while processing expired interrupts: %s", err)
+	}
+
+	report.Add(concurrent)
+	if report != (ExpiryReport{Resumed: 1, Routed: 1, Failed: 1}) {
+		t.Errorf("expected every interrupt to be resolved once, got %+v", report)
+	}
+
+	if executed["resume"] != 1 || executed["route"] != 1 || executed["fail"] != 0 {
+		t.Errorf("expected the resumed nodes to execute once, got %v", executed)
+	}
+}
+
+func TestPipeProcessExpiredNodeError(t *testing.T) {
+	fail := true
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			if _, err := RequestInterrupt(ctx, HITLInterrupt{
+				Message:      "Continue?",
+				Requirements: Requirements{"ok": {Type: Boolean, Default: "true"}},
+				Timeout:      TimeoutPolicy{After: time.Hour, Action: TimeoutResume},
+			}, func(map[string]string) error { return nil }); err != nil {
+				return state, err
+			}
+
+			if fail {
+				return state, errors.New("node failed")
+			}
+
+			return state, nil
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	pipe := NewPipe("deadlines", graph, NewInMemoryStore[State]()).WithClock(clock)
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the flow to be interrupted")
+	}
+
+	clock.Advance(2 * time.Hour)
+
+	report, err := pipe.ProcessExpired(t.Context())
+	if err == nil || report != (ExpiryReport{}) {
+		t.Errorf("expected the node error without resumed executions, got %+v and %v", report, err)
+	}
+
+	fail = false
+	report, err = pipe.ProcessExpired(t.Context())
+	if err != nil {
+		t.Fatalf("error while processing the expired interrupt again: %s", err)
+	}
+
+	if report != (ExpiryReport{Resumed: 1}) {
+		t.Errorf("expected the execution to be resumed, got %+v", report)
+	}
+}
+
+func TestPipeProcessExpiredRejectedDefaults(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			_, err := RequestInterrupt(ctx, HITLInterrupt{
+				Message:      "How many?",
+				Requirements: Requirements{"count": {Type: Integer, Default: "0"}},
+				Timeout:      TimeoutPolicy{After: time.Hour, Action: TimeoutResume},
+			}, func(map[string]string) error { return errors.New("count must be positive") })
+			return state, err
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("deadlines", graph, store).WithClock(clock)
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the flow to be interrupted")
+	}
+
+	clock.Advance(2 * time.Hour)
+
+	report, err := pipe.ProcessExpired(t.Context())
+	if err != nil {
+		t.Fatalf("error while processing expired interrupts: %s", err)
+	}
+
+	if report != (ExpiryReport{Failed: 1}) {
+		t.Errorf("expected the execution to fail, got %+v", report)
+	}
+
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "deadlines"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cs := es.CheckpointState; cs.Status != StatusFailed || len(cs.InterruptHistory) != 1 {
+		t.Errorf("expected the failed execution with the timeout resolution, got %+v", cs)
+	}
+}
@@ -14,6 +14,18 @@ type EdgeResolver[T any] interface {
 	Resolve(ctx context.Context, state T) string
 }
 
+// redirector is implemented by the edges which can route the execution using the
+// value of a [ConitionalInterrupt].
+type redirector interface {
+	redirect(value string) (string, bool)
+}
+
+// keyedResolver is implemented by the edges resolving the next node by a key, so the flow
+// can report why an edge was taken, see [Transition].
+type keyedResolver[T any] interface {
+	resolveKey(ctx context.Context, state T) (key string, next string)
+}
+
 // ConstEdge is simple implementation of the EdgeResolver which
 // returns a constant next node id no matter what the current the state.
 type ConstEdge[T any] string
@@ -30,7 +42,8 @@ func (c ConstEdge[T]) Resolve(ctx context.Context, state T) string {
 }
 
 // ConditionalEdge is used to redirect to different branches based on the
-// value returned by the [ConditionalNode].
+// value returned by the [ConditionalNode], or by the value of the [ConitionalInterrupt]
+// returned by the node.
 type ConditionalEdge[T any] struct {
 	exec         ConditionalNode[T]
 	redirections map[string]string
@@ -47,10 +60,24 @@ func (ce ConditionalEdge[T]) edges() []string {
 
 // Resolve implements the [EdgeResolver] interface for [ConditionalEdge].
 func (ce ConditionalEdge[T]) Resolve(ctx context.Context, state T) string {
-	next, ok := ce.redirections[ce.exec.Execute(ctx, state)]
-	if !ok {
-		return ""
+	_, next := ce.resolveKey(ctx, state)
+	return next
+}
+
+// resolveKey returns the key returned by the conditional node and the node it redirects to.
+func (ce ConditionalEdge[T]) resolveKey(ctx context.Context, state T) (string, string) {
+	if ce.exec == nil {
+		return "", ""
 	}
 
-	return next
+	key := ce.exec.Execute(ctx, state)
+	next, _ := ce.redirect(key)
+
+	return key, next
+}
+
+// redirect returns the target node of the passed redirection value.
+func (ce ConditionalEdge[T]) redirect(value string) (string, bool) {
+	next, ok := ce.redirections[value]
+	return next, ok
 }
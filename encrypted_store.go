@@ -0,0 +1,319 @@
+package flodk
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownKey is returned by a [KeyProvider] when no key is found for a key ID.
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// KeyProvider provides the AES keys used by the [EncryptedStore]. Rotating the current
+// key only affects newly persisted states, older states are decrypted with the key ID
+// stored along with their ciphertext.
+type KeyProvider interface {
+	// CurrentKey returns the ID and the key used to encrypt new payloads.
+	CurrentKey(ctx context.Context) (keyID string, key []byte, err error)
+	// Key returns the key for the passed key ID.
+	Key(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// StaticKeys is a [KeyProvider] backed by a fixed set of keys. The keys must be
+// 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+type StaticKeys struct {
+	// Current is the ID of the key used for encryption.
+	Current string
+	// Keys holds all the keys keyed by their IDs.
+	Keys map[string][]byte
+}
+
+// CurrentKey implements the [KeyProvider] interface for [StaticKeys].
+func (sk StaticKeys) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := sk.Key(ctx, sk.Current)
+	return sk.Current, key, err
+}
+
+// Key implements the [KeyProvider] interface for [StaticKeys].
+func (sk StaticKeys) Key(ctx context.Context, keyID string) ([]byte, error) {
+	key, ok := sk.Keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// Sealed is the encrypted form of the execution persisted by the [EncryptedStore].
+type Sealed struct {
+	KeyID      string `json:"key_id"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// DecryptionError is returned when a persisted application state couldn't be decrypted.
+type DecryptionError struct {
+	ID    ExecutionID
+	KeyID string
+	Err   error
+}
+
+// Error implements the error interface for the decryption error.
+func (de DecryptionError) Error() string {
+	return fmt.Sprintf("decrypting state of %s:%s with key '%s': %s", de.ID.ID, de.ID.FlowName, de.KeyID, de.Err)
+}
+
+// Unwrap returns the underlying error.
+func (de DecryptionError) Unwrap() error {
+	return de.Err
+}
+
+// EncryptedStore wraps a [Store] to encrypt the application state and the checkpoint
+// state with AES-GCM before they're persisted. Only the status and the update time of
+// the execution are stored in clear.
+type EncryptedStore[T any] struct {
+	inner Store[Sealed]
+	keys  KeyProvider
+}
+
+// NewEncryptedStore creates a new [EncryptedStore] persisting into the passed store.
+func NewEncryptedStore[T any](inner Store[Sealed], keys KeyProvider) *EncryptedStore[T] {
+	return &EncryptedStore[T]{
+		inner: inner,
+		keys:  keys,
+	}
+}
+
+// sealedExecution is the plaintext encrypted into a [Sealed].
+type sealedExecution struct {
+	CheckpointState  CheckpointState `json:"checkpoint_state"`
+	ApplicationState json.RawMessage `json:"application_state"`
+}
+
+// clearCheckpoint returns the part of the checkpoint state stored in clear.
+func clearCheckpoint(cs CheckpointState) CheckpointState {
+	return CheckpointState{
+		Status:    cs.Status,
+		UpdatedAt: cs.UpdatedAt,
+	}
+}
+
+// additionalData binds the ciphertext to the execution, so that it can't be
+// copied over to another execution. The IDs are length prefixed, so that
+// different executions never share the same additional data.
+func additionalData(id ExecutionID) []byte {
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(id.FlowName)))
+	data = append(data, id.FlowName...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(id.ID)))
+
+	return append(data, id.ID...)
+}
+
+// gcm creates the AES-GCM AEAD for the passed key.
+func gcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the passed payload with the current key.
+func (s *EncryptedStore[T]) seal(ctx context.Context, id ExecutionID, payload []byte) (Sealed, error) {
+	keyID, key, err := s.keys.CurrentKey(ctx)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	aead, err := gcm(key)
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{
+		KeyID:      keyID,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, payload, additionalData(id)),
+	}, nil
+}
+
+// open decrypts the sealed payload with the key it was encrypted with.
+func (s *EncryptedStore[T]) open(ctx context.Context, id ExecutionID, sealed Sealed) ([]byte, error) {
+	key, err := s.keys.Key(ctx, sealed.KeyID)
+	if err != nil {
+		return nil, DecryptionError{ID: id, KeyID: sealed.KeyID, Err: err}
+	}
+
+	aead, err := gcm(key)
+	if err != nil {
+		return nil, DecryptionError{ID: id, KeyID: sealed.KeyID, Err: err}
+	}
+
+	payload, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, additionalData(id))
+	if err != nil {
+		return nil, DecryptionError{ID: id, KeyID: sealed.KeyID, Err: err}
+	}
+
+	return payload, nil
+}
+
+// GetRaw implements the [RawStore] interface for [EncryptedStore].
+func (s *EncryptedStore[T]) GetRaw(ctx context.Context, id ExecutionID) (RawExecutionState, error) {
+	state, err := s.inner.Get(ctx, id)
+	if err != nil {
+		return RawExecutionState{}, err
+	}
+
+	raw := RawExecutionState{
+		SchemaVersion: state.SchemaVersion,
+		Revision:      state.Revision,
+	}
+
+	payload, err := s.open(ctx, id, state.ApplicationState)
+	if err != nil {
+		return raw, err
+	}
+
+	execution := sealedExecution{}
+	if err := json.Unmarshal(payload, &execution); err != nil {
+		return raw, DecryptionError{ID: id, KeyID: state.ApplicationState.KeyID, Err: err}
+	}
+
+	raw.CheckpointState = execution.CheckpointState
+	raw.ApplicationState = execution.ApplicationState
+
+	return raw, nil
+}
+
+// Get implements the [Store.Get] method of the [Store] interface.
+func (s *EncryptedStore[T]) Get(ctx context.Context, id ExecutionID) (ExecutionState[T], error) {
+	state := ExecutionState[T]{}
+
+	raw, err := s.GetRaw(ctx, id)
+	if err != nil {
+		return state, err
+	}
+
+	state.CheckpointState = raw.CheckpointState
+	state.SchemaVersion = raw.SchemaVersion
+	state.Revision = raw.Revision
+
+	if err := json.Unmarshal(raw.ApplicationState, &state.ApplicationState); err != nil {
+		return state, DecryptionError{ID: id, Err: err}
+	}
+
+	return state, nil
+}
+
+// sealState encrypts the application and checkpoint state of the passed execution state.
+func (s *EncryptedStore[T]) sealState(ctx context.Context, id ExecutionID, state ExecutionState[T]) (ExecutionState[Sealed], error) {
+	appState, err := json.Marshal(state.ApplicationState)
+	if err != nil {
+		return ExecutionState[Sealed]{}, err
+	}
+
+	payload, err := json.Marshal(sealedExecution{
+		CheckpointState:  state.CheckpointState,
+		ApplicationState: appState,
+	})
+	if err != nil {
+		return ExecutionState[Sealed]{}, err
+	}
+
+	sealed, err := s.seal(ctx, id, payload)
+	if err != nil {
+		return ExecutionState[Sealed]{}, err
+	}
+
+	return ExecutionState[Sealed]{
+		CheckpointState:  clearCheckpoint(state.CheckpointState),
+		ApplicationState: sealed,
+		SchemaVersion:    state.SchemaVersion,
+		Revision:         state.Revision,
+	}, nil
+}
+
+// Set implements the [Store.Set] method of the [Store] interface.
+func (s *EncryptedStore[T]) Set(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	sealed, err := s.sealState(ctx, id, state)
+	if err != nil {
+		return err
+	}
+
+	return s.inner.Set(ctx, id, sealed)
+}
+
+// CompareAndSwap implements the [CompareAndSwapper] interface when the wrapped store implements it.
+func (s *EncryptedStore[T]) CompareAndSwap(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	cas, ok := s.inner.(CompareAndSwapper[Sealed])
+	if !ok {
+		return ErrStoreUnsupported
+	}
+
+	sealed, err := s.sealState(ctx, id, state)
+	if err != nil {
+		return err
+	}
+
+	return cas.CompareAndSwap(ctx, id, sealed)
+}
+
+// Reencrypt re-encrypts the persisted execution state with the current key. Use this after
+// a key rotation to retire the older keys. When the wrapped store implements [CompareAndSwapper],
+// [ErrRevisionConflict] is returned if the execution was persisted concurrently.
+func (s *EncryptedStore[T]) Reencrypt(ctx context.Context, id ExecutionID) error {
+	state, err := s.inner.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	payload, err := s.open(ctx, id, state.ApplicationState)
+	if err != nil {
+		return err
+	}
+
+	state.ApplicationState, err = s.seal(ctx, id, payload)
+	if err != nil {
+		return err
+	}
+
+	if cas, ok := s.inner.(CompareAndSwapper[Sealed]); ok {
+		err := cas.CompareAndSwap(ctx, id, state)
+		if !errors.Is(err, ErrStoreUnsupported) {
+			return err
+		}
+	}
+
+	return s.inner.Set(ctx, id, state)
+}
+
+// List implements the [Lister] interface when the wrapped store implements it.
+func (s *EncryptedStore[T]) List(ctx context.Context, flowName string) ([]ExecutionID, error) {
+	lister, ok := s.inner.(Lister)
+	if !ok {
+		return nil, ErrStoreUnsupported
+	}
+
+	return lister.List(ctx, flowName)
+}
+
+// Delete implements the [Deleter] interface when the wrapped store implements it.
+func (s *EncryptedStore[T]) Delete(ctx context.Context, id ExecutionID) error {
+	deleter, ok := s.inner.(Deleter)
+	if !ok {
+		return ErrStoreUnsupported
+	}
+
+	return deleter.Delete(ctx, id)
+}
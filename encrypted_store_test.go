@@ -0,0 +1,152 @@
+package flodk
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type contactState struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+func TestEncryptedStoreRoundTrip(t *testing.T) {
+	inner := NewInMemoryStore[Sealed]()
+	keys := StaticKeys{
+		Current: "k1",
+		Keys: map[string][]byte{
+			"k1": bytes.Repeat([]byte{1}, 32),
+			"k2": bytes.Repeat([]byte{2}, 32),
+		},
+	}
+	store := NewEncryptedStore[contactState](inner, keys)
+	id := ExecutionID{ID: "thread-1", FlowName: "contacts"}
+	state := ExecutionState[contactState]{
+		CheckpointState: CheckpointState{
+			CheckpointID: "a",
+			InterruptHistory: []ResolvedHITLInterrupt{
+				{HITLInterrupt: HITLInterrupt{Reason: "confirm_phone"}, Values: map[string]string{"phone": "+1 555 0199"}},
+			},
+		},
+		ApplicationState: contactState{Name: "Jane Doe", Phone: "+1 555 0100"},
+	}
+
+	if err := store.Set(t.Context(), id, state); err != nil {
+		t.Fatalf("error while setting the state: %s", err)
+	}
+
+	sealed, _ := inner.Get(t.Context(), id)
+	if sealed.ApplicationState.KeyID != "k1" || bytes.Contains(sealed.ApplicationState.Ciphertext, []byte("Jane")) {
+		t.Errorf("expected the state to be encrypted with k1, got %+v", sealed.ApplicationState)
+	}
+
+	if history := sealed.CheckpointState.InterruptHistory; history != nil || sealed.CheckpointState.CheckpointID != "" {
+		t.Errorf("expected the checkpoint state to be persisted encrypted only, got %+v", sealed.CheckpointState)
+	}
+
+	if state.CheckpointState.InterruptHistory[0].Values == nil {
+		t.Errorf("expected the passed state to be left untouched")
+	}
+
+	// Rotate the key, older states are still readable and can be re-encrypted.
+	keys.Current = "k2"
+	store = NewEncryptedStore[contactState](inner, keys)
+	if err := store.Reencrypt(t.Context(), id); err != nil {
+		t.Fatalf("error while re-encrypting the state: %s", err)
+	}
+
+	got, err := store.Get(t.Context(), id)
+	if err != nil {
+		t.Fatalf("error while getting the state: %s", err)
+	}
+
+	if got.ApplicationState != state.ApplicationState {
+		t.Errorf("expected %+v, got %+v", state.ApplicationState, got.ApplicationState)
+	}
+
+	if phone := got.CheckpointState.InterruptHistory[0].Values["phone"]; phone != "+1 555 0199" {
+		t.Errorf("expected the decrypted answer values, got %q", phone)
+	}
+
+	sealed, _ = inner.Get(t.Context(), id)
+	if sealed.ApplicationState.KeyID != "k2" {
+		t.Errorf("expected the state to be re-encrypted with k2, got %s", sealed.ApplicationState.KeyID)
+	}
+}
+
+func TestEncryptedStoreDecryptionError(t *testing.T) {
+	graph, _ := NewGraphBuilder[contactState]().
+		AddNode("a", Noop[contactState]()).
+		SetStartNode("a").
+		Build()
+
+	inner := NewInMemoryStore[Sealed]()
+	id := ExecutionID{ID: "thread-1", FlowName: "contacts"}
+	_ = NewEncryptedStore[contactState](inner, StaticKeys{
+		Current: "k1",
+		Keys:    map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)},
+	}).Set(t.Context(), id, ExecutionState[contactState]{CheckpointState: CheckpointState{CheckpointID: "a"}})
+
+	store := NewEncryptedStore[contactState](inner, StaticKeys{
+		Current: "k2",
+		Keys:    map[string][]byte{"k2": bytes.Repeat([]byte{2}, 32)},
+	})
+
+	_, err := NewPipe("contacts", graph, store).Continue(t.Context(), id.ID, ResumeConfig{})
+
+	var decErr DecryptionError
+	if !errors.As(err, &decErr) || decErr.KeyID != "k1" || !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected DecryptionError for unknown key k1, got %v", err)
+	}
+}
+
+func TestEncryptedStoreBindsExecution(t *testing.T) {
+	inner := NewInMemoryStore[Sealed]()
+	store := NewEncryptedStore[contactState](inner, StaticKeys{
+		Current: "k1",
+		Keys:    map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)},
+	})
+
+	// Both IDs used to join to "a:b:c".
+	from := ExecutionID{ID: "b:c", FlowName: "a"}
+	to := ExecutionID{ID: "c", FlowName: "a:b"}
+	_ = store.Set(t.Context(), from, ExecutionState[contactState]{ApplicationState: contactState{Name: "Jane Doe"}})
+
+	sealed, _ := inner.Get(t.Context(), from)
+	_ = inner.Set(t.Context(), to, sealed)
+
+	var decErr DecryptionError
+	if _, err := store.Get(t.Context(), to); !errors.As(err, &decErr) {
+		t.Errorf("expected DecryptionError for a copied ciphertext, got %v", err)
+	}
+}
+
+func TestEncryptedStoreReencryptConflict(t *testing.T) {
+	inner := &racingStore[Sealed]{InMemoryStore: NewInMemoryStore[Sealed]()}
+	keys := StaticKeys{
+		Current: "k1",
+		Keys: map[string][]byte{
+			"k1": bytes.Repeat([]byte{1}, 32),
+			"k2": bytes.Repeat([]byte{2}, 32),
+		},
+	}
+	store := NewEncryptedStore[contactState](inner, keys)
+	id := ExecutionID{ID: "thread-1", FlowName: "contacts"}
+	_ = store.Set(t.Context(), id, ExecutionState[contactState]{ApplicationState: contactState{Name: "Jane Doe"}})
+
+	// The execution is persisted while it's re-encrypted.
+	inner.race = func() {
+		_ = store.Set(t.Context(), id, ExecutionState[contactState]{ApplicationState: contactState{Name: "John Doe"}})
+	}
+
+	keys.Current = "k2"
+	if err := NewEncryptedStore[contactState](inner, keys).Reencrypt(t.Context(), id); !errors.Is(err, ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict, got %v", err)
+	}
+
+	got, _ := store.Get(t.Context(), id)
+	if got.ApplicationState.Name != "John Doe" {
+		t.Errorf("expected the concurrent write to be kept, got %+v", got.ApplicationState)
+	}
+}
@@ -1,6 +1,7 @@
 package flodk
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -19,6 +20,12 @@ type ErrRequirementInvalidValue struct {
 	Key         string
 	Value       string
 	Suggestions string
+	// Choices are the suggestions the value must be one of, Suggestions is their
+	// joined form used in the error message.
+	Choices []string
+	// Reason describes the violated constraint for requirements which aren't
+	// limited to the suggestions.
+	Reason string
 }
 
 func RequirementInvalid(key string, value string, suggestions []string) ErrRequirementInvalidValue {
@@ -26,9 +33,225 @@ func RequirementInvalid(key string, value string, suggestions []string) ErrRequi
 		Key:         key,
 		Value:       value,
 		Suggestions: strings.Join(suggestions, ", "),
+		Choices:     suggestions,
+	}
+}
+
+// RequirementInvalidReason creates a [ErrRequirementInvalidValue] for a value which
+// violates the passed constraint.
+func RequirementInvalidReason(key string, value string, reason string) ErrRequirementInvalidValue {
+	return ErrRequirementInvalidValue{
+		Key:    key,
+		Value:  value,
+		Reason: reason,
 	}
 }
 
 func (iv ErrRequirementInvalidValue) Error() string {
+	if iv.Reason != "" {
+		return fmt.Sprintf("invalid value for %s: %s, %s", iv.Key, iv.Value, iv.Reason)
+	}
+
 	return fmt.Sprintf("invalid value for %s: %s, need one of [%s]", iv.Key, iv.Value, iv.Suggestions)
 }
+
+// ValidationCode is a machine readable classification of a validation failure.
+type ValidationCode string
+
+const (
+	// CodeKeyNotFound is used when a required key is missing or empty.
+	CodeKeyNotFound ValidationCode = "key_not_found"
+	// CodeInvalidValue is used when a value doesn't satisfy the requirement constraints.
+	CodeInvalidValue ValidationCode = "invalid_value"
+	// CodeCustom is used for any other error returned by a validation function.
+	CodeCustom ValidationCode = "custom"
+)
+
+// FieldError describes a validation failure of a single requirement key.
+type FieldError struct {
+	Key         string         `json:"key,omitempty"`
+	Code        ValidationCode `json:"code"`
+	Message     string         `json:"message"`
+	Value       string         `json:"value,omitempty"`
+	Suggestions []string       `json:"suggestions,omitempty"`
+}
+
+// Error implements the error interface for the field error.
+func (fe FieldError) Error() string {
+	return fe.Message
+}
+
+// Unwrap rebuilds the typed requirement error this field error was created from,
+// so that [errors.As] keeps working after the field error is serialized.
+func (fe FieldError) Unwrap() error {
+	switch fe.Code {
+	case CodeKeyNotFound:
+		return RequirementKeyNotFound(fe.Key)
+	case CodeInvalidValue:
+		return RequirementInvalid(fe.Key, fe.Value, fe.Suggestions)
+	}
+
+	return nil
+}
+
+// ValidationError is a serializable validation failure attached to a [HITLInterrupt].
+// Unlike a plain error, it survives a round trip through any [Store] implementation.
+type ValidationError struct {
+	Code        ValidationCode `json:"code"`
+	Message     string         `json:"message"`
+	Fields      []FieldError   `json:"fields,omitempty"`
+	Suggestions []string       `json:"suggestions,omitempty"`
+}
+
+// NewValidationError converts the passed error into a [ValidationError]. Typed requirement
+// errors ([ErrRequirmentKeyNotFound], [ErrRequirementInvalidValue]) and joined errors are
+// converted into their respective field errors. Returns nil for a nil error.
+func NewValidationError(err error) *ValidationError {
+	if err == nil {
+		return nil
+	}
+
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve
+	}
+
+	ve = newFieldsValidationError(fieldErrors(err))
+	ve.Message = err.Error()
+
+	return ve
+}
+
+// newFieldsValidationError creates a [ValidationError] for the passed field errors.
+func newFieldsValidationError(fields []FieldError) *ValidationError {
+	messages := make([]string, 0, len(fields))
+	for _, fe := range fields {
+		messages = append(messages, fe.Message)
+	}
+
+	ve := &ValidationError{
+		Code:    fields[0].Code,
+		Message: strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+
+	if len(fields) == 1 {
+		ve.Suggestions = fields[0].Suggestions
+	}
+
+	return ve
+}
+
+// fieldErrors flattens the passed error into a list of field errors.
+func fieldErrors(err error) []FieldError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		fields := []FieldError{}
+		for _, e := range joined.Unwrap() {
+			fields = append(fields, fieldErrors(e)...)
+		}
+
+		if len(fields) > 0 {
+			return fields
+		}
+	}
+
+	var fe FieldError
+	if errors.As(err, &fe) {
+		return []FieldError{fe}
+	}
+
+	var notFound ErrRequirmentKeyNotFound
+	if errors.As(err, &notFound) {
+		return []FieldError{{
+			Key:     string(notFound),
+			Code:    CodeKeyNotFound,
+			Message: err.Error(),
+		}}
+	}
+
+	var invalid ErrRequirementInvalidValue
+	if errors.As(err, &invalid) {
+		return []FieldError{{
+			Key:         invalid.Key,
+			Code:        CodeInvalidValue,
+			Message:     err.Error(),
+			Value:       invalid.Value,
+			Suggestions: invalid.Choices,
+		}}
+	}
+
+	return []FieldError{{
+		Code:    CodeCustom,
+		Message: err.Error(),
+	}}
+}
+
+// Error implements the error interface for the validation error.
+func (ve *ValidationError) Error() string {
+	return ve.Message
+}
+
+// Unwrap returns the field errors, which makes the typed requirement errors
+// reachable through [errors.As].
+func (ve *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(ve.Fields))
+	for _, fe := range ve.Fields {
+		errs = append(errs, fe)
+	}
+
+	return errs
+}
+
+// NodeNotFoundError is returned when the flow tries to execute a node which doesn't exist in the graph.
+type NodeNotFoundError struct {
+	NodeID       string
+	GraphVersion string
+}
+
+// Error implements the error interface for the node not found error.
+func (nf NodeNotFoundError) Error() string {
+	return fmt.Sprintf("node '%s' not found in graph version '%s'", nf.NodeID, nf.GraphVersion)
+}
+
+// StaleInterruptError is returned when the values passed to [Pipe.Continue] answer an interrupt
+// which isn't pending anymore, see [ResumeConfig.InterruptID].
+type StaleInterruptError struct {
+	InterruptID InterruptID
+	// Pending is the interrupt pending at the time, it's zero when the execution isn't interrupted.
+	Pending InterruptID
+}
+
+// Error implements the error interface for the stale interrupt error.
+func (se StaleInterruptError) Error() string {
+	if se.Pending == (InterruptID{}) {
+		return fmt.Sprintf("interrupt '%s' is stale: no interrupt is pending", se.InterruptID)
+	}
+
+	return fmt.Sprintf("interrupt '%s' is stale: interrupt '%s' is pending", se.InterruptID, se.Pending)
+}
+
+// RouteNotFoundError is returned when a node routes the execution with a [ConitionalInterrupt]
+// value which isn't a redirection of the node's edge, or when a node with routes ([GraphBuilder.AddRoutes])
+// completes without routing the execution, in which case the Value is empty.
+type RouteNotFoundError struct {
+	NodeID string
+	Value  string
+}
+
+// Error implements the error interface for the route not found error.
+func (rn RouteNotFoundError) Error() string {
+	if rn.Value == "" {
+		return fmt.Sprintf("node '%s' didn't route the execution", rn.NodeID)
+	}
+
+	return fmt.Sprintf("no route '%s' from node '%s'", rn.Value, rn.NodeID)
+}
+
+// GraphVersionNotFoundError is returned when an execution is resumed on a pipe which
+// doesn't hold the graph version the execution was started with.
+type GraphVersionNotFoundError string
+
+// Error implements the error interface for the graph version not found error.
+func (gv GraphVersionNotFoundError) Error() string {
+	return "graph version '" + string(gv) + "' not found"
+}
@@ -0,0 +1,76 @@
+package flodk
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestValidationErrorRoundTrip(t *testing.T) {
+	interrupt := HITLInterrupt{
+		Reason:  "journey_details_not_found",
+		Message: "Please input your journey details",
+		ValidationError: NewValidationError(errors.Join(
+			RequirementKeyNotFound("origin"),
+			RequirementInvalid("class", "first", []string{"economy", "business"}),
+		)),
+	}
+
+	bs, err := json.Marshal(interrupt)
+	if err != nil {
+		t.Fatalf("error while marshalling the interrupt: %s", err)
+	}
+
+	var decoded HITLInterrupt
+	if err := json.Unmarshal(bs, &decoded); err != nil {
+		t.Fatalf("error while unmarshalling the interrupt: %s", err)
+	}
+
+	if decoded.ValidationError == nil {
+		t.Fatal("expected validation error to survive the round trip")
+	}
+
+	if decoded.ValidationError.Error() != interrupt.ValidationError.Error() {
+		t.Errorf("expected message %q, got %q", interrupt.ValidationError.Error(), decoded.ValidationError.Error())
+	}
+
+	var notFound ErrRequirmentKeyNotFound
+	if !errors.As(decoded.ValidationError, &notFound) || notFound != "origin" {
+		t.Errorf("expected ErrRequirmentKeyNotFound for origin, got %q", notFound)
+	}
+
+	var invalid ErrRequirementInvalidValue
+	if !errors.As(decoded.ValidationError, &invalid) {
+		t.Fatal("expected ErrRequirementInvalidValue in the validation error")
+	}
+
+	if invalid.Key != "class" || invalid.Value != "first" || invalid.Suggestions != "economy, business" {
+		t.Errorf("unexpected invalid value error: %+v", invalid)
+	}
+}
+
+func TestValidationErrorCustom(t *testing.T) {
+	ve := NewValidationError(errors.New("origin and destination must differ"))
+	if ve.Code != CodeCustom {
+		t.Errorf("expected code %s, got %s", CodeCustom, ve.Code)
+	}
+
+	if NewValidationError(nil) != nil {
+		t.Error("expected nil validation error for nil error")
+	}
+}
+
+func TestValidationErrorSuggestionsWithCommas(t *testing.T) {
+	suggestions := []string{"Chennai, IN", "Bengaluru, IN"}
+	ve := NewValidationError(RequirementInvalid("city", "Delhi", suggestions))
+
+	if !slices.Equal(ve.Fields[0].Suggestions, suggestions) {
+		t.Errorf("expected suggestions %q, got %q", suggestions, ve.Fields[0].Suggestions)
+	}
+
+	var invalid ErrRequirementInvalidValue
+	if !errors.As(ve, &invalid) || !slices.Equal(invalid.Choices, suggestions) {
+		t.Errorf("expected the choices to survive the conversion, got %+v", invalid)
+	}
+}
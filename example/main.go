@@ -16,7 +16,7 @@ import (
 
 type FlightBookingState struct {
 	RawPrompt   string   `json:"prompt"`
-	Name        string   `json:"name" flodk_extraction:"username"`
+	Name        string   `json:"name" flodk_extraction:"username" flodk:"redact"`
 	Origin      string   `json:"origin" flodk_extraction:"origin"`
 	Destination string   `json:"destination" flodk_extraction:"destination"`
 	Flights     []string `json:"flights"`
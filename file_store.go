@@ -0,0 +1,189 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileStore implements the [Store] interface to persist the executions as JSON files in
+// a directory, one file per execution at `<dir>/<flow name>/<id>.json`. It is meant for
+// local development and debugging, as the compare-and-swap is only safe within a single process.
+type FileStore[T any] struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStore creates a new [FileStore] persisting into the passed directory.
+func NewFileStore[T any](dir string) *FileStore[T] {
+	return &FileStore[T]{
+		dir: dir,
+	}
+}
+
+// escapePath escapes the passed ID into a single path element. Dots are escaped as
+// well, so that IDs like `..` can't point outside of the store directory.
+func escapePath(id string) string {
+	return strings.ReplaceAll(url.PathEscape(id), ".", "%2E")
+}
+
+// path returns the file path of the execution.
+func (s *FileStore[T]) path(id ExecutionID) string {
+	return filepath.Join(s.dir, escapePath(id.FlowName), escapePath(id.ID)+".json")
+}
+
+// read reads and decodes the execution file into the passed value.
+func (s *FileStore[T]) read(id ExecutionID, v any) error {
+	bs, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrExecutionNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bs, v)
+}
+
+// write encodes and atomically writes the execution file.
+func (s *FileStore[T]) write(id ExecutionID, state ExecutionState[T]) error {
+	bs, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	path := s.path(id)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(bs); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// revision returns the revision of the persisted execution, 0 if it doesn't exist.
+func (s *FileStore[T]) revision(id ExecutionID) (uint64, error) {
+	var state RawExecutionState
+	err := s.read(id, &state)
+	if errors.Is(err, ErrExecutionNotFound) {
+		return 0, nil
+	}
+
+	return state.Revision, err
+}
+
+// GetRaw implements the [RawStore] interface for [FileStore].
+func (s *FileStore[T]) GetRaw(ctx context.Context, id ExecutionID) (RawExecutionState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state RawExecutionState
+	return state, s.read(id, &state)
+}
+
+// Get implements the [Store.Get] method of the [Store] interface.
+func (s *FileStore[T]) Get(ctx context.Context, id ExecutionID) (ExecutionState[T], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state ExecutionState[T]
+	return state, s.read(id, &state)
+}
+
+// Set implements the [Store.Set] method of the [Store] interface.
+func (s *FileStore[T]) Set(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.revision(id)
+	if err != nil {
+		return err
+	}
+
+	state.Revision = current + 1
+	return s.write(id, state)
+}
+
+// CompareAndSwap implements the [CompareAndSwapper] interface for [FileStore].
+func (s *FileStore[T]) CompareAndSwap(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.revision(id)
+	if err != nil {
+		return err
+	}
+
+	if current != state.Revision {
+		return ErrRevisionConflict
+	}
+
+	state.Revision = current + 1
+	return s.write(id, state)
+}
+
+// List implements the [Lister] interface for [FileStore].
+func (s *FileStore[T]) List(ctx context.Context, flowName string) ([]ExecutionID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join(s.dir, escapePath(flowName)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []ExecutionID{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]ExecutionID, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		id, err := url.PathUnescape(name)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, ExecutionID{ID: id, FlowName: flowName})
+	}
+
+	return ids, nil
+}
+
+// Delete implements the [Deleter] interface for [FileStore].
+func (s *FileStore[T]) Delete(ctx context.Context, id ExecutionID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
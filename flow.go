@@ -3,6 +3,7 @@ package flodk
 import (
 	"context"
 	"errors"
+	"slices"
 )
 
 // FlowCallback is a helper type which will be called during flow execution
@@ -19,13 +20,43 @@ func (fc FlowCallback[T]) Call(cs CheckpointState, runState T) error {
 	return fc(cs, runState)
 }
 
+// suspension is implemented by the errors nodes return to suspend the execution until the
+// pipe resumes it, e.g. [HITLInterrupt], [SignalWait] and [TimerWait]. The suspended node is executed
+// again on resumption.
+type suspension interface {
+	error
+	suspend(cs *CheckpointState)
+}
+
+// Transition describes the edge taken by the flow after executing a node.
+type Transition struct {
+	// From is the executed node.
+	From string
+	// To is the next node, it's empty when the executed node is a terminal node.
+	To string
+	// Key is the value the next node was chosen by: the value returned by the conditional node
+	// of a conditional edge, or the value of the [ConitionalInterrupt] returned by the node.
+	// It's empty for constant edges.
+	Key string
+}
+
 // Flow is a construct used start or resume execution of a graph with the
 // passed initial app and checkpoint state.
 type Flow[T any] struct {
-	name      string
-	graph     Graph[T]
-	execState CheckpointState
+	name       string
+	id         string
+	graph      Graph[T]
+	execState  CheckpointState
+	visitLimit int
 
+	clock Clock
+	idGen IDGenerator
+
+	breakpoints Breakpoints
+	maxSteps    int
+	transition  Transition
+
+	onNodeStart     FlowCallback[T]
 	onNodeExecution FlowCallback[T]
 	onGraphEnd      FlowCallback[T]
 	onInterrupt     FlowCallback[T]
@@ -37,19 +68,75 @@ func NewFlow[T any](
 	graph Graph[T],
 ) *Flow[T] {
 	return &Flow[T]{
-		name:      name,
-		graph:     graph,
-		execState: CheckpointState{},
+		name:       name,
+		graph:      graph,
+		execState:  CheckpointState{},
+		visitLimit: DefaultVisitLimit,
 	}
 }
 
 // WithCheckpoint is used to set the checkpoint state for this flow execution.
 func (f *Flow[T]) WithCheckpoint(cp CheckpointState) *Flow[T] {
+	cp.Visited = cloneVisitLog(cp.Visited)
 	f.execState = cp
 
 	return f
 }
 
+// WithExecutionID sets the unique identifier of this flow execution, see [RunContext].
+func (f *Flow[T]) WithExecutionID(id string) *Flow[T] {
+	f.id = id
+
+	return f
+}
+
+// WithVisitLimit sets the number of visit runs kept in the checkpoint state, see [VisitLog].
+func (f *Flow[T]) WithVisitLimit(limit int) *Flow[T] {
+	f.visitLimit = limit
+
+	return f
+}
+
+// WithClock sets the clock loaded into the context of the nodes, see [GetClock].
+func (f *Flow[T]) WithClock(clock Clock) *Flow[T] {
+	f.clock = clock
+
+	return f
+}
+
+// WithIDGenerator sets the ID generator loaded into the context of the nodes, see [GetIDGenerator].
+func (f *Flow[T]) WithIDGenerator(gen IDGenerator) *Flow[T] {
+	f.idGen = gen
+
+	return f
+}
+
+// WithMaxSteps limits the number of nodes executed by [Flow.Execute]. Once the limit is reached
+// the execution returns with the checkpoint set to the next node. Zero means no limit.
+func (f *Flow[T]) WithMaxSteps(n int) *Flow[T] {
+	f.maxSteps = n
+
+	return f
+}
+
+// Checkpoint returns the checkpoint state of the flow execution.
+func (f *Flow[T]) Checkpoint() CheckpointState {
+	return f.execState
+}
+
+// LastTransition returns the edge taken after the last node executed by the flow. Only From is
+// set when the last node suspended the execution.
+func (f *Flow[T]) LastTransition() Transition {
+	return f.transition
+}
+
+// OnNodeStart sets the callback function to be called when a node is visited, before it's executed.
+func (f *Flow[T]) OnNodeStart(cb FlowCallback[T]) *Flow[T] {
+	f.onNodeStart = cb
+
+	return f
+}
+
 // OnNodeExec sets the callback function to be called after a node is executed.
 func (f *Flow[T]) OnNodeExec(cb FlowCallback[T]) *Flow[T] {
 	f.onNodeExecution = cb
@@ -64,7 +151,8 @@ func (f *Flow[T]) OnGraphEnd(cb FlowCallback[T]) *Flow[T] {
 	return f
 }
 
-// OnInterrupt sets the callback function to be called when the flow is interrupted.
+// OnInterrupt sets the callback function to be called when the flow is interrupted or
+// suspended otherwise, e.g. waiting on a signal.
 func (f *Flow[T]) OnInterrupt(cb FlowCallback[T]) *Flow[T] {
 	f.onInterrupt = cb
 
@@ -74,29 +162,92 @@ func (f *Flow[T]) OnInterrupt(cb FlowCallback[T]) *Flow[T] {
 // Execute executes the graph with provided initial state and resumes based on the passed
 // checkpoint state configuration.
 func (f *Flow[T]) Execute(ctx context.Context, state T) (T, error) {
+	if f.clock != nil {
+		ctx = LoadClock(ctx, f.clock)
+	}
+
+	if f.idGen != nil {
+		ctx = LoadIDGenerator(ctx, f.idGen)
+	}
+
 	currentID := f.graph.start
 	if f.execState.CheckpointID != "" {
 		currentID = f.execState.CheckpointID
 	} else {
 		// Set the current ID from the graph config
 		f.execState.CheckpointID = currentID
+		f.execState.GraphVersion = f.graph.version
 	}
 
 	runState := state
 
+	// When the flow is resumed on a suspended node, the node is executed again as
+	// another attempt of the same step instead of a new visit.
+	resumed := f.execState.Interrupt.InterruptID.NodeID == currentID ||
+		f.execState.Signal.NodeID == currentID ||
+		f.execState.Timer.NodeID == currentID
+
+	// When the flow is resumed on a breakpoint, the node is visited as usual. Only the
+	// breakpoint before the node was already hit, the one after the previous node wasn't.
+	skipBreakpoint := false
+	if resumed && f.execState.Interrupt.isBreakpoint() {
+		skipBreakpoint = f.execState.Interrupt.Reason == BreakpointBefore
+		f.archive(ctx, currentID, nil)
+		resumed = false
+	}
+
+	executed := 0
 	continueRunning := true
 
 	for continueRunning {
-		f.execState.Visited = append(f.execState.Visited, currentID)
+		node, ok := f.graph.nodeMap[currentID]
+		if !ok {
+			return runState, NodeNotFoundError{
+				NodeID:       currentID,
+				GraphVersion: f.graph.version,
+			}
+		}
+
+		if resumed {
+			resumed = false
+			f.execState.Attempt = max(f.execState.Attempt, 1) + 1
+		} else {
+			if !skipBreakpoint && slices.Contains(f.breakpoints.Before, currentID) {
+				return runState, f.pause(breakpoint(ctx, BreakpointBefore, currentID, currentID), runState)
+			}
+
+			skipBreakpoint = false
+			f.execState.Attempt = 1
+			f.execState.Visited.Append(currentID, f.visitLimit)
+			if err := f.onNodeStart.Call(f.execState, runState); err != nil {
+				return runState, err
+			}
+		}
 
 		// Execute the current node.
-		node := f.graph.nodeMap[currentID]
-		currentState, err := node.Execute(LoadNodeID(ctx, currentID), runState)
-		if err != nil {
+		executed++
+		f.transition = Transition{From: currentID}
+		currentState, err := node.Execute(f.nodeContext(ctx, currentID), runState)
+
+		// A conditional interrupt completes the node and routes the execution
+		// using the interrupt value instead of resolving the edge.
+		var route ConitionalInterrupt
+		routed := err != nil && errors.As(err, &route)
+
+		if err != nil && !routed {
+			// Expired interrupts can't be routed to a missing node.
 			var interrupt HITLInterrupt
 			if errors.As(err, &interrupt) {
+				if err := checkFallback(f.graph, interrupt.Timeout); err != nil {
+					return runState, err
+				}
+			}
+
+			var suspended suspension
+			if errors.As(err, &suspended) {
 				runState = currentState
-				f.execState.Interrupt = interrupt
+				f.archive(ctx, currentID, suspended)
+				suspended.suspend(&f.execState)
 				continueRunning = false
 
 				// Callback failures.
@@ -108,20 +259,7 @@ func (f *Flow[T]) Execute(ctx context.Context, state T) (T, error) {
 			return runState, err
 		}
 
-		if f.execState.Interrupt.InterruptID.NodeID == currentID {
-			// If the current node successfully processed the interrupt,
-			// then the interrupt will be pushed into resolved HITL slice
-			// of the execState. The current interrupt will be reset.
-			lint, ok := getLoadedInterrupt(ctx, f.execState.Interrupt)
-			if ok {
-				f.execState.InterruptHistory = append(
-					f.execState.InterruptHistory,
-					lint,
-				)
-			}
-
-			f.execState.Interrupt = HITLInterrupt{}
-		}
+		f.archive(ctx, currentID, nil)
 
 		runState = currentState
 
@@ -132,13 +270,46 @@ func (f *Flow[T]) Execute(ctx context.Context, state T) (T, error) {
 			continue
 		}
 
-		currentID = resolver.Resolve(ctx, runState)
+		executedID := currentID
+		if routed {
+			next, ok := resolver.(redirector)
+			if !ok {
+				return runState, RouteNotFoundError{NodeID: currentID, Value: route.Value}
+			}
+
+			nextID, ok := next.redirect(route.Value)
+			if !ok {
+				return runState, RouteNotFoundError{NodeID: currentID, Value: route.Value}
+			}
+
+			currentID = nextID
+			f.transition.Key = route.Value
+		} else if ce, ok := resolver.(ConditionalEdge[T]); ok && ce.exec == nil {
+			// Edges added with AddRoutes are only taken by nodes routing the execution.
+			return runState, RouteNotFoundError{NodeID: currentID}
+		} else if kr, ok := resolver.(keyedResolver[T]); ok {
+			f.transition.Key, currentID = kr.resolveKey(ctx, runState)
+		} else {
+			currentID = resolver.Resolve(ctx, runState)
+		}
+
+		f.transition.To = currentID
 		f.execState.CheckpointID = currentID
+		f.execState.Status = StatusRunning
 		if err := f.onNodeExecution.Call(f.execState, runState); err != nil {
 			return runState, err
 		}
+
+		if currentID != "" && slices.Contains(f.breakpoints.After, executedID) {
+			return runState, f.pause(breakpoint(ctx, BreakpointAfter, executedID, currentID), runState)
+		}
+
+		if f.maxSteps > 0 && executed >= f.maxSteps && currentID != "" {
+			return runState, nil
+		}
 	}
 
+	f.execState.Status = StatusCompleted
 	if err := f.onGraphEnd.Call(f.execState, runState); err != nil {
 		return runState, err
 	}
@@ -146,18 +317,116 @@ func (f *Flow[T]) Execute(ctx context.Context, state T) (T, error) {
 	return runState, nil
 }
 
-// Name returns the name of the flow.
-func (f *Flow[T]) Name() string {
-	return f.name
+// pause suspends the execution at the current checkpoint with the interrupt of a breakpoint.
+func (f *Flow[T]) pause(interrupt HITLInterrupt, runState T) error {
+	interrupt.suspend(&f.execState)
+	if err := f.onInterrupt.Call(f.execState, runState); err != nil {
+		return err
+	}
+
+	return interrupt
 }
 
-// LoadNodeID is used to store the current graph node id (node name) into the passed context.
-func LoadNodeID(ctx context.Context, nodeID string) context.Context {
-	return context.WithValue(ctx, "current_node", nodeID)
+// archive moves the pending interrupt, signal and timer of the node into their histories once the
+// node processed them successfully, i.e. the node completed or suspended the execution again
+// for another reason. The next suspension is nil when the node completed.
+func (f *Flow[T]) archive(ctx context.Context, nodeID string, next suspension) {
+	if pending := f.execState.Interrupt.InterruptID; pending.NodeID == nodeID {
+		if it, ok := next.(HITLInterrupt); !ok || it.InterruptID != pending {
+			if lint, ok := getLoadedInterrupt(ctx, nodeID, pending.Key); ok {
+				lint.Step = f.execState.Visited.Len()
+				f.execState.InterruptHistory = append(f.execState.InterruptHistory, lint)
+			}
+
+			f.execState.Interrupt = HITLInterrupt{}
+		}
+	}
+
+	if pending := f.execState.Signal; pending.NodeID == nodeID {
+		if sw, ok := next.(SignalWait); !ok || sw != pending {
+			if rs, ok := getLoadedSignal(ctx, nodeID, pending.Name); ok {
+				rs.Step = f.execState.Visited.Len()
+				f.execState.SignalHistory = append(f.execState.SignalHistory, rs)
+			}
+
+			f.execState.Signal = SignalWait{}
+		}
+	}
+
+	if pending := f.execState.Timer; pending.NodeID == nodeID {
+		if tw, ok := next.(TimerWait); !ok || tw.Name != pending.Name {
+			if ft, ok := getLoadedTimer(ctx, nodeID, pending.Name); ok {
+				ft.Step = f.execState.Visited.Len()
+				f.execState.TimerHistory = append(f.execState.TimerHistory, ft)
+			}
+
+			f.execState.Timer = TimerWait{}
+		}
+	}
 }
 
-// GetNodeID is used to retrieve the current node id (node name) from the context.
-func GetNodeID(ctx context.Context) (string, bool) {
-	val, ok := ctx.Value("current_node").(string)
-	return val, ok
+// nodeContext loads the passed context with the node ID and the [RunContext] of the node.
+// The interrupts, signals and timers the node was resumed with earlier in the same step are
+// replayed from the histories, so nodes suspending several times get all the prior answers back.
+func (f *Flow[T]) nodeContext(ctx context.Context, nodeID string) context.Context {
+	pending := f.execState.Interrupt.InterruptID
+	for _, ri := range f.execState.InterruptHistory {
+		if ri.InterruptID.NodeID != nodeID || ri.Step != f.execState.Visited.Len() {
+			continue
+		}
+
+		if pending.NodeID == nodeID && ri.InterruptID.Key == pending.Key {
+			continue
+		}
+
+		ctx = loadResolvedInterrupt(ctx, ri)
+	}
+
+	for _, rs := range f.execState.SignalHistory {
+		if rs.NodeID != nodeID || rs.Step != f.execState.Visited.Len() {
+			continue
+		}
+
+		if f.execState.Signal.NodeID == nodeID && rs.Name == f.execState.Signal.Name {
+			continue
+		}
+
+		ctx = loadSignal(ctx, rs)
+	}
+
+	for _, ft := range f.execState.TimerHistory {
+		if ft.NodeID != nodeID || ft.Step != f.execState.Visited.Len() {
+			continue
+		}
+
+		if f.execState.Timer.NodeID == nodeID && ft.Name == f.execState.Timer.Name {
+			continue
+		}
+
+		ctx = loadTimer(ctx, ft)
+	}
+
+	rc := RunContext{
+		ExecutionID: ExecutionID{
+			ID:       f.id,
+			FlowName: f.name,
+		},
+		NodeID:       nodeID,
+		Step:         f.execState.Visited.Len(),
+		Attempt:      f.execState.Attempt,
+		GraphVersion: f.execState.GraphVersion,
+	}
+
+	if pending.NodeID == nodeID {
+		if ri, ok := getLoadedInterrupt(ctx, nodeID, pending.Key); ok {
+			rc.Interrupt = &ri
+		}
+	}
+
+	return context.WithValue(LoadNodeID(ctx, nodeID), runContextKey{}, rc)
+}
+
+// Name returns the name of the flow.
+func (f *Flow[T]) Name() string {
+	return f.name
 }
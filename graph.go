@@ -13,7 +13,16 @@ type Graph[T any] struct {
 	nodeMap map[string]Node[T]
 	edges   map[string]EdgeResolver[T]
 
-	start string
+	start   string
+	version string
+	// durable holds the nodes after which the execution state must be
+	// persisted, see [PersistDurable].
+	durable map[string]bool
+}
+
+// Version returns the version identifier of the graph.
+func (g Graph[T]) Version() string {
+	return g.version
 }
 
 // GraphBuilder is a helper type which contains methods to build a graph.
@@ -28,6 +37,7 @@ func NewGraphBuilder[T any]() *GraphBuilder[T] {
 		g: Graph[T]{
 			nodeMap: make(map[string]Node[T]),
 			edges:   make(map[string]EdgeResolver[T]),
+			durable: make(map[string]bool),
 		},
 	}
 }
@@ -87,6 +97,13 @@ func (gb *GraphBuilder[T]) AddConditionalEdge(start string, end ConditionalNode[
 	return gb
 }
 
+// AddRoutes adds the edges of a node which routes the execution itself by returning a
+// [ConitionalInterrupt], e.g. a [Review] node. The value of the interrupt is the key of
+// the redirections. A [RouteNotFoundError] is returned when the node completes without routing.
+func (gb *GraphBuilder[T]) AddRoutes(start string, redirections map[string]string) *GraphBuilder[T] {
+	return gb.AddConditionalEdge(start, nil, redirections)
+}
+
 // SetStartNode sets the start node of the graph.
 func (gb *GraphBuilder[T]) SetStartNode(start string) *GraphBuilder[T] {
 	if start == "" {
@@ -103,6 +120,28 @@ func (gb *GraphBuilder[T]) SetStartNode(start string) *GraphBuilder[T] {
 	return gb
 }
 
+// MarkDurable marks the nodes after which the execution state is persisted
+// when the pipe uses the [PersistDurable] persistence mode.
+func (gb *GraphBuilder[T]) MarkDurable(names ...string) *GraphBuilder[T] {
+	for _, name := range names {
+		if _, ok := gb.g.nodeMap[name]; !ok {
+			fmt.Fprintf(os.Stderr, "durable node not found: %s, skipping", name)
+			continue
+		}
+
+		gb.g.durable[name] = true
+	}
+
+	return gb
+}
+
+// SetVersion sets the version identifier of the graph. The version is recorded in every
+// execution of the graph, so that a [Pipe] can resume the execution on the same graph version.
+func (gb *GraphBuilder[T]) SetVersion(version string) *GraphBuilder[T] {
+	gb.g.version = version
+	return gb
+}
+
 // Build checks for the validity of the graph and returns the graph.
 func (gb *GraphBuilder[T]) Build() (Graph[T], error) {
 	if gb.g.start == "" {
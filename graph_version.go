@@ -0,0 +1,139 @@
+package flodk
+
+import (
+	"context"
+	"fmt"
+)
+
+// GraphMigration rewrites the checkpoint state of an execution when it's moved from one
+// graph version to another, for example to remap renamed or removed node IDs.
+type GraphMigration func(ctx context.Context, cs CheckpointState) (CheckpointState, error)
+
+// graphMigrationKey identifies a migration between two graph versions.
+type graphMigrationKey struct {
+	from string
+	to   string
+}
+
+// AddGraphVersion registers an additional graph version with the pipe. Executions are
+// always resumed on the graph version they were started with, so older graph versions
+// must stay registered as long as there are in-flight executions on them.
+// New executions always start on the graph passed to [NewPipe].
+func (p *Pipe[T]) AddGraphVersion(graph Graph[T]) *Pipe[T] {
+	p.graphs[graph.version] = graph
+
+	return p
+}
+
+// AddGraphMigration registers a migration used by [Pipe.MigrateExecution] to move
+// executions from the graph version `from` to the graph version `to`.
+func (p *Pipe[T]) AddGraphMigration(from, to string, m GraphMigration) *Pipe[T] {
+	if p.graphMigrations == nil {
+		p.graphMigrations = make(map[graphMigrationKey]GraphMigration)
+	}
+
+	p.graphMigrations[graphMigrationKey{from: from, to: to}] = m
+
+	return p
+}
+
+// graphFor returns the graph version the passed checkpoint state was started with.
+func (p *Pipe[T]) graphFor(cs CheckpointState) (Graph[T], error) {
+	graph, ok := p.graphs[cs.GraphVersion]
+	if !ok {
+		return Graph[T]{}, GraphVersionNotFoundError(cs.GraphVersion)
+	}
+
+	return graph, nil
+}
+
+// MigrateExecution deliberately moves an execution to another registered graph version
+// using the migration registered with [Pipe.AddGraphMigration]. The checkpoint of the
+// migrated execution must point to a node which exists in the target graph version.
+func (p *Pipe[T]) MigrateExecution(ctx context.Context, id string, version string) error {
+	execID := ExecutionID{
+		ID:       id,
+		FlowName: p.name,
+	}
+
+	execState, err := p.load(ctx, execID)
+	if err != nil {
+		return err
+	}
+
+	from := execState.CheckpointState.GraphVersion
+	if from == version {
+		return nil
+	}
+
+	graph, ok := p.graphs[version]
+	if !ok {
+		return GraphVersionNotFoundError(version)
+	}
+
+	m, ok := p.graphMigrations[graphMigrationKey{from: from, to: version}]
+	if !ok {
+		return fmt.Errorf("graph version '%s' to '%s': %w", from, version, ErrMigrationNotFound)
+	}
+
+	cs, err := m(ctx, execState.CheckpointState)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := graph.nodeMap[cs.CheckpointID]; !ok {
+		return NodeNotFoundError{
+			NodeID:       cs.CheckpointID,
+			GraphVersion: version,
+		}
+	}
+
+	cs.GraphVersion = version
+	execState.CheckpointState = cs
+
+	return p.store.Set(ctx, execID, execState)
+}
+
+// RemapNodes returns a [GraphMigration] which renames the node IDs referenced by the
+// checkpoint state using the passed old to new node ID mapping. Node IDs not present
+// in the mapping are left untouched.
+func RemapNodes(mapping map[string]string) GraphMigration {
+	remap := func(nodeID string) string {
+		if to, ok := mapping[nodeID]; ok {
+			return to
+		}
+
+		return nodeID
+	}
+
+	return func(ctx context.Context, cs CheckpointState) (CheckpointState, error) {
+		cs.CheckpointID = remap(cs.CheckpointID)
+		cs.Interrupt.InterruptID.NodeID = remap(cs.Interrupt.InterruptID.NodeID)
+		cs.Visited = cs.Visited.Remap(remap)
+
+		history := make([]ResolvedHITLInterrupt, 0, len(cs.InterruptHistory))
+		for _, ri := range cs.InterruptHistory {
+			ri.InterruptID.NodeID = remap(ri.InterruptID.NodeID)
+			history = append(history, ri)
+		}
+		cs.InterruptHistory = history
+
+		cs.Signal.NodeID = remap(cs.Signal.NodeID)
+		signals := make([]ReceivedSignal, 0, len(cs.SignalHistory))
+		for _, rs := range cs.SignalHistory {
+			rs.NodeID = remap(rs.NodeID)
+			signals = append(signals, rs)
+		}
+		cs.SignalHistory = signals
+
+		cs.Timer.NodeID = remap(cs.Timer.NodeID)
+		timers := make([]FiredTimer, 0, len(cs.TimerHistory))
+		for _, ft := range cs.TimerHistory {
+			ft.NodeID = remap(ft.NodeID)
+			timers = append(timers, ft)
+		}
+		cs.TimerHistory = timers
+
+		return cs, nil
+	}
+}
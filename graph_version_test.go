@@ -0,0 +1,164 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func askNode(label string) Node[State] {
+	return FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+		if _, err := Interrupt(ctx, "Continue?", "confirm", Requirements{"ok": {Type: Custom}}); err != nil {
+			return state, err
+		}
+
+		state.sum += len(label)
+		return state, nil
+	})
+}
+
+func versionedGraph(t *testing.T, version, askID, label string) Graph[State] {
+	graph, err := NewGraphBuilder[State]().
+		AddNode(askID, askNode(label)).
+		AddNode("end", Noop[State]()).
+		AddEdge(askID, "end").
+		SetStartNode(askID).
+		SetVersion(version).
+		Build()
+	if err != nil {
+		t.Fatalf("error while building graph %s: %s", version, err)
+	}
+
+	return graph
+}
+
+func TestPipeResumesOnStartedGraphVersion(t *testing.T) {
+	store := NewInMemoryStore[State]()
+	v1 := versionedGraph(t, "v1", "ask", "a")
+	v2 := versionedGraph(t, "v2", "confirm", "bb")
+
+	_, err := NewPipe("versions", v1, store).Invoke(t.Context(), "thread-1", State{})
+	var interrupt HITLInterrupt
+	if !errors.As(err, &interrupt) {
+		t.Fatalf("expected interrupt, got %v", err)
+	}
+
+	// Deploy v2 which renamed the `ask` node, v1 is kept for the in-flight executions.
+	pipe := NewPipe("versions", v2, store).AddGraphVersion(v1)
+	resume := ResumeConfig{InterruptValues: map[string]string{"ok": "yes"}}
+
+	state, err := pipe.Continue(t.Context(), "thread-1", resume)
+	if err != nil {
+		t.Fatalf("error while continuing on v1: %s", err)
+	}
+
+	if state.sum != 1 {
+		t.Errorf("expected execution to resume on v1, got sum %d", state.sum)
+	}
+
+	// Without v1 the execution can't be resumed.
+	if _, err := NewPipe("versions", v2, store).Invoke(t.Context(), "thread-2", State{}); !errors.As(err, &interrupt) {
+		t.Fatalf("expected interrupt, got %v", err)
+	}
+
+	_, err = NewPipe("versions", v1, store).Continue(t.Context(), "thread-2", resume)
+	var versionErr GraphVersionNotFoundError
+	if !errors.As(err, &versionErr) || versionErr != "v2" {
+		t.Errorf("expected GraphVersionNotFoundError for v2, got %v", err)
+	}
+}
+
+func TestPipeMigrateExecution(t *testing.T) {
+	store := NewInMemoryStore[State]()
+	v1 := versionedGraph(t, "v1", "ask", "a")
+	v2 := versionedGraph(t, "v2", "confirm", "bb")
+
+	if _, err := NewPipe("versions", v1, store).Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected interrupt, got nil")
+	}
+
+	pipe := NewPipe("versions", v2, store).
+		AddGraphVersion(v1).
+		AddGraphMigration("v1", "v2", RemapNodes(map[string]string{"ask": "confirm"}))
+
+	if err := pipe.MigrateExecution(t.Context(), "thread-1", "v2"); err != nil {
+		t.Fatalf("error while migrating the execution: %s", err)
+	}
+
+	state, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"ok": "yes"}})
+	if err != nil {
+		t.Fatalf("error while continuing on v2: %s", err)
+	}
+
+	if state.sum != 2 {
+		t.Errorf("expected execution to resume on v2, got sum %d", state.sum)
+	}
+}
+
+func TestRemapNodes(t *testing.T) {
+	cs, err := RemapNodes(map[string]string{"pay": "payment"})(t.Context(), CheckpointState{
+		CheckpointID:  "pay",
+		Visited:       NewVisitLog("pay"),
+		Signal:        SignalWait{NodeID: "pay", Name: "paid"},
+		SignalHistory: []ReceivedSignal{{SignalWait: SignalWait{NodeID: "pay", Name: "authorized"}}},
+		Timer:         TimerWait{NodeID: "pay", Name: "retry"},
+		TimerHistory:  []FiredTimer{{TimerWait: TimerWait{NodeID: "pay", Name: "backoff"}}},
+	})
+	if err != nil {
+		t.Fatalf("error while remapping the nodes: %s", err)
+	}
+
+	if cs.CheckpointID != "payment" || cs.Visited.Last() != "payment" {
+		t.Errorf("expected the checkpoint to be remapped, got %+v", cs)
+	}
+
+	if cs.Signal.NodeID != "payment" || cs.SignalHistory[0].NodeID != "payment" {
+		t.Errorf("expected the signals to be remapped, got %+v and %+v", cs.Signal, cs.SignalHistory)
+	}
+
+	if cs.Timer.NodeID != "payment" || cs.TimerHistory[0].NodeID != "payment" {
+		t.Errorf("expected the timers to be remapped, got %+v and %+v", cs.Timer, cs.TimerHistory)
+	}
+}
+
+func TestPipeMigrateSleepingExecution(t *testing.T) {
+	sleeping := func(version, nodeID string) Graph[State] {
+		graph, err := NewGraphBuilder[State]().
+			AddNode(nodeID, SleepNode[State]("nap", time.Hour)).
+			AddNode("end", AdderNode(1)).
+			AddEdge(nodeID, "end").
+			SetStartNode(nodeID).
+			SetVersion(version).
+			Build()
+		if err != nil {
+			t.Fatalf("error while building graph %s: %s", version, err)
+		}
+
+		return graph
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := NewInMemoryStore[State]()
+	if _, err := NewPipe("versions", sleeping("v1", "sleep"), store).WithClock(clock).Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the execution to sleep")
+	}
+
+	pipe := NewPipe("versions", sleeping("v2", "rest"), store).
+		WithClock(clock).
+		AddGraphMigration("v1", "v2", RemapNodes(map[string]string{"sleep": "rest"}))
+
+	if err := pipe.MigrateExecution(t.Context(), "thread-1", "v2"); err != nil {
+		t.Fatalf("error while migrating the execution: %s", err)
+	}
+
+	clock.Advance(time.Hour)
+	state, err := pipe.Wake(t.Context(), "thread-1")
+	if err != nil {
+		t.Fatalf("error while waking the execution on v2: %s", err)
+	}
+
+	if state.sum != 1 {
+		t.Errorf("expected the execution to complete on v2, got sum %d", state.sum)
+	}
+}
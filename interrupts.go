@@ -1,12 +1,18 @@
 package flodk
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // InterruptID is used to identify the interrupt against the Node which
 // threw the interrupt.
 type InterruptID struct {
 	NodeID string `json:"node_id"`
 	ID     string `json:"id"`
+	// Key identifies the interrupt among the interrupts raised by the node, see [InterruptWithKey].
+	Key string `json:"key,omitempty"`
 }
 
 // String returns the string representation of the interrupt.
@@ -23,7 +29,23 @@ const (
 	// Custom requirements can input any text as the interrupt value.
 	Custom RequirementTypes = "custom"
 	// CustomWithSuggestions requirements can input any with a given suggestions as hints.
+	// Values matching a suggestion case-insensitively are normalized to the suggestion.
 	CustomWithSuggestions RequirementTypes = "custom_with_suggestions"
+	// Integer requirements accept whole numbers within the optional Min and Max bounds.
+	Integer RequirementTypes = "integer"
+	// Decimal requirements accept decimal numbers within the optional Min and Max bounds.
+	Decimal RequirementTypes = "decimal"
+	// Boolean requirements accept true/false, yes/no, y/n, on/off and 1/0.
+	Boolean RequirementTypes = "boolean"
+	// DateTime requirements accept a date/time in the layout set in Format.
+	DateTime RequirementTypes = "datetime"
+	// Pattern requirements accept text fully matching the regular expression set in Pattern.
+	Pattern RequirementTypes = "pattern"
+	// MultiSelect requirements accept a list of values chosen from the provided suggestions,
+	// either as a JSON array or as comma separated text. Min and Max bound the number of choices.
+	MultiSelect RequirementTypes = "multi_select"
+	// JSON requirements accept any valid JSON document.
+	JSON RequirementTypes = "json"
 )
 
 // Requirement defines the constaints for the interrupt requirements.
@@ -32,36 +54,67 @@ type Requirement struct {
 	Type RequirementTypes `json:"type"`
 	// Suggestions for the values of the requirement.
 	Suggestions []string `json:"suggestions"`
+	// Min is the lower bound of Integer and Decimal values, or the minimum number of
+	// choices of MultiSelect values.
+	Min *float64 `json:"min,omitempty"`
+	// Max is the upper bound of Integer and Decimal values, or the maximum number of
+	// choices of MultiSelect values.
+	Max *float64 `json:"max,omitempty"`
+	// Format is the Go time layout of DateTime values, defaults to [time.RFC3339].
+	Format string `json:"format,omitempty"`
+	// Pattern is the regular expression Pattern values must fully match.
+	Pattern string `json:"pattern,omitempty"`
+	// Optional requirements may be left empty.
+	Optional bool `json:"optional,omitempty"`
+	// Default is the value used when the requirement is left empty.
+	Default string `json:"default,omitempty"`
+	// Validator is an additional validation of the coerced value. Validators aren't persisted
+	// with the interrupt, so they only run when the node validates the resolved interrupt.
+	Validator func(value string) error `json:"-"`
+
+	// Label is the human readable name of the requirement shown by forms.
+	Label string `json:"label,omitempty"`
+	// Description is the help text of the requirement shown by forms.
+	Description string `json:"description,omitempty"`
+	// Placeholder is the example text shown by forms in empty inputs.
+	Placeholder string `json:"placeholder,omitempty"`
+	// Order is the position of the requirement in forms, as the Requirements map is unordered.
+	// Requirements are ordered by Order and then by key.
+	Order int `json:"order,omitempty"`
 }
 
 // Requirements is hash map of all the requirements which needs input from the user.
 type Requirements map[string]Requirement
 
-// Validate method does a basic validation on the provided values against the constrainsts
-// defined in the Requirements.
+// Validate method validates the provided values against all the constrainsts defined in the
+// Requirements. See [Requirements.Resolve].
 func (r Requirements) Validate(values map[string]string) error {
-	for k := range r {
-		data, ok := values[k]
-		if !ok {
-			return RequirementKeyNotFound(k)
-		}
-
-		if data == "" {
-			return RequirementKeyNotFound(k)
-		}
-	}
-
-	return nil
+	_, err := r.Resolve(values)
+	return err
 }
 
 // HITLInterrupt is used to return a invoke a human in the loop
 // routine as a part of the flow.
 type HITLInterrupt struct {
-	Reason          string       `json:"reason"`
-	Message         string       `json:"message"`
-	ValidationError error        `json:"validation_error"`
-	Requirements    Requirements `json:"requirements"`
-	InterruptID     InterruptID  `json:"interrupt_id"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// ValidationError is set when the values submitted for a previous resumption
+	// failed the validation of the node.
+	ValidationError *ValidationError `json:"validation_error,omitempty"`
+	Requirements    Requirements     `json:"requirements"`
+	InterruptID     InterruptID      `json:"interrupt_id"`
+	// Data is the JSON document shown to the user along with the interrupt, e.g. the
+	// state fields of a [Review].
+	Data json.RawMessage `json:"data,omitempty"`
+	// ExpiresAt is the time after which the interrupt is resolved by its Timeout policy,
+	// see [Pipe.ProcessExpired]. Interrupts without an expiry time wait forever.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+	// Timeout is the policy applied when the interrupt expires.
+	Timeout TimeoutPolicy `json:"timeout,omitzero"`
+	// Assignee is the ID of the [Principal] the interrupt is assigned to.
+	Assignee string `json:"assignee,omitempty"`
+	// Roles are the roles allowed to resolve the interrupt.
+	Roles []string `json:"roles,omitempty"`
 }
 
 // Error implements the error interface for the task interrupt.
@@ -69,9 +122,16 @@ func (it HITLInterrupt) Error() string {
 	return fmt.Sprintf("flow interrupted: %s", it.Reason)
 }
 
+// suspend implements the suspension interface for [HITLInterrupt].
+func (it HITLInterrupt) suspend(cs *CheckpointState) {
+	cs.Interrupt = it
+	cs.Status = StatusInterrupted
+}
+
 // ConditionalInterrupt is used to direct the execution of a flow
 // using a alias value. This value will then be used to choose the
-// next edge of the graph.
+// next edge of the graph, see [GraphBuilder.AddRoutes]. The state
+// returned along with the interrupt is kept.
 type ConitionalInterrupt struct {
 	Value string
 }
@@ -0,0 +1,137 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrMigrationNotFound is returned when no [Migration] is registered for a schema version
+// which is older than the current schema version of the [Pipe].
+var ErrMigrationNotFound = errors.New("migration not found")
+
+// ErrSchemaVersionUnsupported is returned when the persisted state has a schema version newer
+// than the current schema version of the [Pipe].
+var ErrSchemaVersionUnsupported = errors.New("schema version is newer than the current schema version")
+
+// Migration upgrades a persisted application state payload by a single schema version.
+type Migration func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error)
+
+// MigrationError is returned when a persisted application state couldn't be upgraded
+// to the current schema version.
+type MigrationError struct {
+	From int
+	To   int
+	Err  error
+}
+
+// Error implements the error interface for the migration error.
+func (me MigrationError) Error() string {
+	return fmt.Sprintf("migrating state schema from v%d to v%d: %s", me.From, me.To, me.Err)
+}
+
+// Unwrap returns the underlying error.
+func (me MigrationError) Unwrap() error {
+	return me.Err
+}
+
+// WithSchemaVersion sets the current schema version of the application state. Every state
+// persisted by the pipe is tagged with this version.
+func (p *Pipe[T]) WithSchemaVersion(version int) *Pipe[T] {
+	p.schemaVersion = version
+
+	return p
+}
+
+// AddMigration registers a migration which upgrades the application state payload from
+// the schema version `from` to `from+1`. Migrations are applied in order when an older
+// execution state is loaded from the store. The store must implement [RawStore], older
+// states loaded from other stores fail with [ErrStoreUnsupported].
+func (p *Pipe[T]) AddMigration(from int, m Migration) *Pipe[T] {
+	if p.migrations == nil {
+		p.migrations = make(map[int]Migration)
+	}
+
+	p.migrations[from] = m
+
+	return p
+}
+
+// load fetches the execution state from the store and upgrades it to the current schema
+// version. Migrations need the raw payload, so they're only applied for stores implementing
+// [RawStore].
+func (p *Pipe[T]) load(ctx context.Context, id ExecutionID) (ExecutionState[T], error) {
+	if rs, ok := p.store.(RawStore); ok && len(p.migrations) > 0 {
+		raw, err := rs.GetRaw(ctx, id)
+		if err != nil {
+			var zero ExecutionState[T]
+			return zero, err
+		}
+
+		return p.migrate(ctx, raw)
+	}
+
+	state, err := p.store.Get(ctx, id)
+	if err != nil || state.SchemaVersion == p.schemaVersion {
+		return state, err
+	}
+
+	// Typed stores already decoded the payload into the current state struct, which
+	// drops renamed and removed fields, so the migrations can't be applied.
+	migrationErr := MigrationError{From: state.SchemaVersion, To: p.schemaVersion, Err: ErrStoreUnsupported}
+	if state.SchemaVersion > p.schemaVersion {
+		migrationErr.Err = ErrSchemaVersionUnsupported
+		return state, migrationErr
+	}
+
+	for v := state.SchemaVersion; v < p.schemaVersion; v++ {
+		if _, ok := p.migrations[v]; !ok {
+			return state, MigrationError{From: v, To: v + 1, Err: ErrMigrationNotFound}
+		}
+	}
+
+	return state, migrationErr
+}
+
+// migrate applies all the registered migrations from the persisted schema version up to the
+// current schema version and decodes the application state.
+func (p *Pipe[T]) migrate(ctx context.Context, raw RawExecutionState) (ExecutionState[T], error) {
+	state := ExecutionState[T]{
+		CheckpointState: raw.CheckpointState,
+		SchemaVersion:   raw.SchemaVersion,
+		Revision:        raw.Revision,
+	}
+
+	if raw.SchemaVersion > p.schemaVersion {
+		return state, MigrationError{
+			From: raw.SchemaVersion,
+			To:   p.schemaVersion,
+			Err:  ErrSchemaVersionUnsupported,
+		}
+	}
+
+	payload := raw.ApplicationState
+	for v := raw.SchemaVersion; v < p.schemaVersion; v++ {
+		m, ok := p.migrations[v]
+		if !ok {
+			return state, MigrationError{From: v, To: v + 1, Err: ErrMigrationNotFound}
+		}
+
+		var err error
+		payload, err = m(ctx, payload)
+		if err != nil {
+			return state, MigrationError{From: v, To: v + 1, Err: err}
+		}
+	}
+
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &state.ApplicationState); err != nil {
+			return state, MigrationError{From: raw.SchemaVersion, To: p.schemaVersion, Err: err}
+		}
+	}
+
+	state.SchemaVersion = p.schemaVersion
+
+	return state, nil
+}
@@ -0,0 +1,157 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// jsonStore is a serializing store used to test the raw payload handling.
+type jsonStore[T any] struct {
+	states map[string][]byte
+}
+
+func newJSONStore[T any]() *jsonStore[T] {
+	return &jsonStore[T]{states: make(map[string][]byte)}
+}
+
+func (s *jsonStore[T]) GetRaw(ctx context.Context, id ExecutionID) (RawExecutionState, error) {
+	var state RawExecutionState
+	bs, ok := s.states[id.ID+":"+id.FlowName]
+	if !ok {
+		return state, ErrExecutionNotFound
+	}
+
+	return state, json.Unmarshal(bs, &state)
+}
+
+func (s *jsonStore[T]) Get(ctx context.Context, id ExecutionID) (ExecutionState[T], error) {
+	var state ExecutionState[T]
+	bs, ok := s.states[id.ID+":"+id.FlowName]
+	if !ok {
+		return state, ErrExecutionNotFound
+	}
+
+	return state, json.Unmarshal(bs, &state)
+}
+
+func (s *jsonStore[T]) Set(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	bs, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	s.states[id.ID+":"+id.FlowName] = bs
+	return nil
+}
+
+type passengerState struct {
+	FullName string `json:"full_name"`
+	Greeting string `json:"greeting"`
+}
+
+func TestPipeMigratesOlderSchema(t *testing.T) {
+	graph, err := NewGraphBuilder[passengerState]().
+		AddNode("greet", FunctionNode[passengerState](func(ctx context.Context, state passengerState) (passengerState, error) {
+			if _, err := Interrupt(ctx, "Greet?", "confirm_greeting", Requirements{"ok": {Type: Custom}}); err != nil {
+				return state, err
+			}
+
+			state.Greeting = "Hello " + state.FullName
+			return state, nil
+		})).
+		SetStartNode("greet").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := newJSONStore[passengerState]()
+	id := ExecutionID{ID: "thread-1", FlowName: "greeting"}
+
+	// Persist a state written by the v0 state struct, which used `name`.
+	store.states[id.ID+":"+id.FlowName] = []byte(`{
+		"checkpoint_state": {
+			"checkpoint_id": "greet",
+			"interrupt": {"reason": "confirm_greeting", "requirements": {"ok": {"type": "custom"}}, "interrupt_id": {"node_id": "greet", "id": "1"}}
+		},
+		"application_state": {"name": "Jane Doe"},
+		"schema_version": 0
+	}`)
+
+	pipe := NewPipe("greeting", graph, store).
+		WithSchemaVersion(1).
+		AddMigration(0, func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+			old := map[string]any{}
+			if err := json.Unmarshal(payload, &old); err != nil {
+				return nil, err
+			}
+
+			old["full_name"] = old["name"]
+			delete(old, "name")
+
+			return json.Marshal(old)
+		})
+
+	state, err := pipe.Continue(t.Context(), id.ID, ResumeConfig{InterruptValues: map[string]string{"ok": "yes"}})
+	if err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	if state.Greeting != "Hello Jane Doe" {
+		t.Errorf("expected migrated greeting, got %q", state.Greeting)
+	}
+
+	persisted, err := store.Get(t.Context(), id)
+	if err != nil {
+		t.Fatalf("error while fetching the state: %s", err)
+	}
+
+	if persisted.SchemaVersion != 1 {
+		t.Errorf("expected persisted schema version 1, got %d", persisted.SchemaVersion)
+	}
+}
+
+func TestPipeMissingMigration(t *testing.T) {
+	graph, _ := NewGraphBuilder[passengerState]().
+		AddNode("greet", Noop[passengerState]()).
+		SetStartNode("greet").
+		Build()
+
+	store := NewInMemoryStore[passengerState]()
+	id := ExecutionID{ID: "thread-1", FlowName: "greeting"}
+	_ = store.Set(t.Context(), id, ExecutionState[passengerState]{
+		CheckpointState: CheckpointState{CheckpointID: "greet"},
+	})
+
+	_, err := NewPipe("greeting", graph, store).
+		WithSchemaVersion(2).
+		Continue(t.Context(), id.ID, ResumeConfig{})
+	if !errors.Is(err, ErrMigrationNotFound) {
+		t.Errorf("expected ErrMigrationNotFound, got %v", err)
+	}
+}
+
+func TestPipeMigrationNeedsRawStore(t *testing.T) {
+	graph, _ := NewGraphBuilder[passengerState]().
+		AddNode("greet", Noop[passengerState]()).
+		SetStartNode("greet").
+		Build()
+
+	store := NewInMemoryStore[passengerState]()
+	id := ExecutionID{ID: "thread-1", FlowName: "greeting"}
+	_ = store.Set(t.Context(), id, ExecutionState[passengerState]{
+		CheckpointState: CheckpointState{CheckpointID: "greet"},
+	})
+
+	_, err := NewPipe("greeting", graph, store).
+		WithSchemaVersion(1).
+		AddMigration(0, func(ctx context.Context, payload json.RawMessage) (json.RawMessage, error) {
+			return payload, nil
+		}).
+		Continue(t.Context(), id.ID, ResumeConfig{})
+	if !errors.Is(err, ErrStoreUnsupported) {
+		t.Errorf("expected ErrStoreUnsupported, got %v", err)
+	}
+}
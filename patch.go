@@ -0,0 +1,170 @@
+package flodk
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// ApplyMergePatch applies a JSON merge patch (RFC 7396) to the JSON encoding of the state.
+// Only the top level fields named by the patch are replaced, the other fields of the state,
+// including the ones not encoded as JSON, are kept as is. The passed state is left untouched,
+// even when T is a pointer.
+func ApplyMergePatch[T any](state T, patch []byte) (T, error) {
+	changes := map[string]json.RawMessage{}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return state, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	doc, err := jsonObject(state)
+	if err != nil {
+		return state, err
+	}
+
+	fields := make(map[string]any, len(changes))
+	for key, change := range changes {
+		var value any
+		if err := json.Unmarshal(change, &value); err != nil {
+			return state, fmt.Errorf("invalid merge patch: %w", err)
+		}
+
+		// Removed fields are decoded as null.
+		fields[key] = mergePatch(doc[key], value)
+	}
+
+	bs, err := json.Marshal(fields)
+	if err != nil {
+		return state, err
+	}
+
+	// Decode into a copy of the state with the patched fields reset, so the decoder
+	// doesn't write into the maps and pointers shared with the passed state.
+	keys := slices.Collect(maps.Keys(changes))
+	patched := state
+	val := reflect.ValueOf(&patched).Elem()
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		copied := reflect.New(val.Type().Elem())
+		copied.Elem().Set(val.Elem())
+		val.Set(copied)
+		val = copied.Elem()
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		resetFields(val, keys)
+	case reflect.Map:
+		copied := reflect.MakeMapWithSize(val.Type(), val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), iter.Value())
+		}
+
+		for _, key := range keys {
+			copied.SetMapIndex(reflect.ValueOf(key).Convert(val.Type().Key()), reflect.Value{})
+		}
+		val.Set(copied)
+	}
+
+	if err := json.Unmarshal(bs, &patched); err != nil {
+		return state, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	return patched, nil
+}
+
+// mergePatch merges the patch into the target as described in RFC 7396.
+func mergePatch(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	doc, ok := target.(map[string]any)
+	if !ok {
+		doc = map[string]any{}
+	}
+
+	for key, change := range changes {
+		if change == nil {
+			delete(doc, key)
+			continue
+		}
+
+		doc[key] = mergePatch(doc[key], change)
+	}
+
+	return doc
+}
+
+// jsonObject returns the JSON encoding of the state as an object.
+func jsonObject[T any](state T) (map[string]any, error) {
+	bs, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]any{}
+	if err := json.Unmarshal(bs, &doc); err != nil {
+		return nil, fmt.Errorf("state is not a JSON object: %w", err)
+	}
+
+	return doc, nil
+}
+
+// resetFields zeroes the fields of the struct the decoder fills from the patch keys, including
+// the fields promoted from embedded structs. Like the decoder, the keys match the field names
+// case-insensitively. Embedded struct pointers are copied before their fields are reset.
+func resetFields(val reflect.Value, keys []string) {
+	typ := val.Type()
+	for i := range typ.NumField() {
+		field, fieldVal := typ.Field(i), val.Field(i)
+		if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			switch {
+			case field.Type.Kind() == reflect.Struct:
+				// The decoder fills the promoted fields of unexported embedded structs too.
+				if !fieldVal.CanSet() {
+					fieldVal = reflect.NewAt(field.Type, unsafe.Pointer(fieldVal.UnsafeAddr())).Elem()
+				}
+				resetFields(fieldVal, keys)
+				continue
+			case !fieldVal.CanSet():
+				continue
+			case field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct:
+				if !fieldVal.IsNil() {
+					copied := reflect.New(field.Type.Elem())
+					copied.Elem().Set(fieldVal.Elem())
+					fieldVal.Set(copied)
+					resetFields(copied.Elem(), keys)
+				}
+				continue
+			}
+		}
+
+		if !fieldVal.CanSet() {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		if slices.ContainsFunc(keys, func(key string) bool { return strings.EqualFold(key, name) }) {
+			fieldVal.SetZero()
+		}
+	}
+}
+
+// jsonFieldName returns the name of the struct field in its JSON encoding.
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
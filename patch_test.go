@@ -0,0 +1,64 @@
+package flodk
+
+import (
+	"maps"
+	"testing"
+)
+
+type patchAudit struct {
+	Tags map[string]string `json:"tags"`
+}
+
+type patchState struct {
+	patchAudit
+	Name  string            `json:"name"`
+	Notes map[string]string `json:"notes"`
+	count int
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	state := patchState{
+		patchAudit: patchAudit{Tags: map[string]string{"vip": "yes"}},
+		Name:       "Jane Doe",
+		Notes:      map[string]string{"seat": "window"},
+		count:      3,
+	}
+
+	patched, err := ApplyMergePatch(state, []byte(`{"NAME": "John Doe", "notes": {"meal": "veg"}, "tags": {"vip": null}}`))
+	if err != nil {
+		t.Fatalf("error while applying the patch: %s", err)
+	}
+
+	if patched.Name != "John Doe" || patched.count != 3 {
+		t.Errorf("expected the name to be patched and the other fields kept, got %+v", patched)
+	}
+
+	if want := map[string]string{"seat": "window", "meal": "veg"}; !maps.Equal(patched.Notes, want) {
+		t.Errorf("expected notes %v, got %v", want, patched.Notes)
+	}
+
+	if len(patched.Tags) != 0 {
+		t.Errorf("expected the promoted tags to be patched, got %v", patched.Tags)
+	}
+
+	if state.Name != "Jane Doe" || len(state.Notes) != 1 || state.Tags["vip"] != "yes" {
+		t.Errorf("expected the passed state to be left untouched, got %+v", state)
+	}
+}
+
+func TestApplyMergePatchPointer(t *testing.T) {
+	state := &patchState{Name: "Jane Doe", Notes: map[string]string{"seat": "window"}}
+
+	patched, err := ApplyMergePatch(state, []byte(`{"name": "John Doe", "notes": {"meal": "veg"}}`))
+	if err != nil {
+		t.Fatalf("error while applying the patch: %s", err)
+	}
+
+	if patched == state || patched.Name != "John Doe" || len(patched.Notes) != 2 {
+		t.Errorf("expected a patched copy, got %+v", patched)
+	}
+
+	if state.Name != "Jane Doe" || len(state.Notes) != 1 {
+		t.Errorf("expected the passed state to be left untouched, got %+v", state)
+	}
+}
@@ -0,0 +1,117 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrExecutionNotRunning is returned when recovering an execution which isn't running.
+	ErrExecutionNotRunning = errors.New("execution is not running")
+	// ErrInvalidPersistencePolicy is returned when executing a pipe with an invalid [PersistencePolicy].
+	ErrInvalidPersistencePolicy = errors.New("invalid persistence policy")
+)
+
+// PersistMode defines when the pipe persists the execution state while the graph is executed.
+type PersistMode string
+
+const (
+	// PersistEveryNode persists the execution state after every node. This is the default.
+	PersistEveryNode PersistMode = "every_node"
+	// PersistOnInterrupt persists the execution state only when the flow is interrupted
+	// and when the graph execution is completed.
+	PersistOnInterrupt PersistMode = "on_interrupt"
+	// PersistEveryN persists the execution state after every N executed nodes.
+	PersistEveryN PersistMode = "every_n"
+	// PersistDurable persists the execution state only after the nodes marked
+	// with [GraphBuilder.MarkDurable].
+	PersistDurable PersistMode = "durable"
+)
+
+// PersistencePolicy defines how often the execution state is persisted. The state is always
+// persisted on interrupts and when the graph execution is completed. Unless every node is
+// persisted, the initial checkpoint of new executions is persisted as well, so that they can
+// be recovered.
+//
+// Persisting less often trades durability for latency: if the process crashes, the execution
+// can only be recovered ([Pipe.Recover]) from the last persisted checkpoint, and all the nodes
+// executed after it are executed again.
+type PersistencePolicy struct {
+	Mode PersistMode
+	// N is the number of nodes between two persisted checkpoints for [PersistEveryN].
+	// It must be at least 1.
+	N int
+}
+
+// validate checks the policy, see [ErrInvalidPersistencePolicy].
+func (pp PersistencePolicy) validate() error {
+	if pp.Mode == PersistEveryN && pp.N < 1 {
+		return fmt.Errorf("%w: N must be at least 1, got %d", ErrInvalidPersistencePolicy, pp.N)
+	}
+
+	return nil
+}
+
+// persistsEveryNode reports whether the policy persists the state after every node.
+func (pp PersistencePolicy) persistsEveryNode() bool {
+	return pp.Mode == "" || pp.Mode == PersistEveryNode || (pp.Mode == PersistEveryN && pp.N == 1)
+}
+
+// WithPersistence sets the persistence policy of the pipe. Invalid policies are reported
+// with [ErrInvalidPersistencePolicy] when the pipe is executed.
+func (p *Pipe[T]) WithPersistence(policy PersistencePolicy) *Pipe[T] {
+	p.persistence = policy
+
+	return p
+}
+
+// nodeExecCallback wraps the persist callback so that it is only called after
+// the nodes selected by the persistence policy of the pipe.
+func (p *Pipe[T]) nodeExecCallback(graph Graph[T], persist FlowCallback[T]) FlowCallback[T] {
+	policy := p.persistence
+	executed := 0
+
+	return func(cs CheckpointState, runState T) error {
+		executed++
+
+		switch policy.Mode {
+		case PersistOnInterrupt:
+			return nil
+		case PersistEveryN:
+			if executed%policy.N != 0 {
+				return nil
+			}
+		case PersistDurable:
+			if !graph.durable[cs.Visited.Last()] {
+				return nil
+			}
+		}
+
+		return persist(cs, runState)
+	}
+}
+
+// Recover resumes an execution which didn't finish, for example because the process crashed,
+// from its last persisted checkpoint. Nodes executed after the checkpoint are executed again.
+// [ErrExecutionNotRunning] is returned for interrupted, completed or expired executions.
+func (p *Pipe[T]) Recover(ctx context.Context, id string) (T, error) {
+	execState, err := p.load(ctx, ExecutionID{
+		ID:       id,
+		FlowName: p.name,
+	})
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	if execState.CheckpointState.Status != StatusRunning {
+		return execState.ApplicationState, ErrExecutionNotRunning
+	}
+
+	graph, err := p.graphFor(execState.CheckpointState)
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	return p.invoke(ctx, id, graph, execState.CheckpointState, execState.ApplicationState)
+}
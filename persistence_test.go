@@ -0,0 +1,157 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type countingStore[T any] struct {
+	*InMemoryStore[T]
+	sets int
+}
+
+func (s *countingStore[T]) Set(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	s.sets++
+	return s.InMemoryStore.Set(ctx, id, state)
+}
+
+func TestPersistencePolicy(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		AddNode("b", AdderNode(2)).
+		AddNode("c", AdderNode(3)).
+		AddNode("end", Noop[State]()).
+		AddEdge("a", "b").
+		AddEdge("b", "c").
+		AddEdge("c", "end").
+		MarkDurable("b").
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	tests := map[string]struct {
+		policy PersistencePolicy
+		sets   int
+	}{
+		"default":      {policy: PersistencePolicy{}, sets: 4},
+		"every_node":   {policy: PersistencePolicy{Mode: PersistEveryNode}, sets: 4},
+		"on_interrupt": {policy: PersistencePolicy{Mode: PersistOnInterrupt}, sets: 2},
+		"every_1":      {policy: PersistencePolicy{Mode: PersistEveryN, N: 1}, sets: 4},
+		"every_n":      {policy: PersistencePolicy{Mode: PersistEveryN, N: 2}, sets: 3},
+		"durable":      {policy: PersistencePolicy{Mode: PersistDurable}, sets: 3},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &countingStore[State]{InMemoryStore: NewInMemoryStore[State]()}
+			pipe := NewPipe("persistence", graph, store).WithPersistence(tc.policy)
+
+			state, err := pipe.Invoke(t.Context(), "thread-1", State{})
+			if err != nil {
+				t.Fatalf("error while invoking the flow: %s", err)
+			}
+
+			if state.sum != 6 {
+				t.Errorf("expected sum 6, got %d", state.sum)
+			}
+
+			if store.sets != tc.sets {
+				t.Errorf("expected %d persisted checkpoints, got %d", tc.sets, store.sets)
+			}
+		})
+	}
+}
+
+func TestPersistencePolicyInvalidN(t *testing.T) {
+	graph, _ := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		SetStartNode("a").
+		Build()
+
+	_, err := NewPipe("persistence", graph, NewInMemoryStore[State]()).
+		WithPersistence(PersistencePolicy{Mode: PersistEveryN}).
+		Invoke(t.Context(), "thread-1", State{})
+	if !errors.Is(err, ErrInvalidPersistencePolicy) {
+		t.Errorf("expected ErrInvalidPersistencePolicy, got %v", err)
+	}
+}
+
+func TestPipeRecover(t *testing.T) {
+	crash := true
+	build := func() Graph[State] {
+		graph, err := NewGraphBuilder[State]().
+			AddNode("a", AdderNode(1)).
+			AddNode("b", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+				if crash {
+					return state, errors.New("crashed")
+				}
+
+				state.sum += 2
+				return state, nil
+			})).
+			AddEdge("a", "b").
+			SetStartNode("a").
+			Build()
+		if err != nil {
+			t.Fatalf("error while building the graph: %s", err)
+		}
+
+		return graph
+	}
+
+	pipe := NewPipe("recover", build(), NewInMemoryStore[State]())
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the execution to crash")
+	}
+
+	crash = false
+	state, err := pipe.Recover(t.Context(), "thread-1")
+	if err != nil {
+		t.Fatalf("error while recovering the execution: %s", err)
+	}
+
+	if state.sum != 3 {
+		t.Errorf("expected the execution to resume after node a, got sum %d", state.sum)
+	}
+
+	if _, err := pipe.Recover(t.Context(), "thread-1"); !errors.Is(err, ErrExecutionNotRunning) {
+		t.Errorf("expected ErrExecutionNotRunning for a completed execution, got %v", err)
+	}
+}
+
+func TestPipeRecoverOnInterruptPolicy(t *testing.T) {
+	crash := true
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			if crash {
+				return state, errors.New("crashed")
+			}
+
+			state.sum++
+			return state, nil
+		})).
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("recover", graph, NewInMemoryStore[State]()).
+		WithPersistence(PersistencePolicy{Mode: PersistOnInterrupt})
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{sum: 1}); err == nil {
+		t.Fatal("expected the execution to crash")
+	}
+
+	crash = false
+	state, err := pipe.Recover(t.Context(), "thread-1")
+	if err != nil {
+		t.Fatalf("error while recovering the execution: %s", err)
+	}
+
+	if state.sum != 2 {
+		t.Errorf("expected the execution to restart from the initial checkpoint, got sum %d", state.sum)
+	}
+}
@@ -2,11 +2,11 @@ package flodk
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand/v2"
+	"maps"
 	"slices"
-	"time"
 )
 
 // Pipe is a graph execution supervisor which loads the necessary
@@ -17,6 +17,26 @@ type Pipe[T any] struct {
 	name  string
 	graph Graph[T]
 	store Store[T]
+
+	// graphs holds all the graph versions known to this pipe keyed by the version.
+	graphs          map[string]Graph[T]
+	graphMigrations map[graphMigrationKey]GraphMigration
+
+	schemaVersion int
+	migrations    map[int]Migration
+
+	retention   RetentionPolicy[T]
+	redactor    Redactor[T]
+	persistence PersistencePolicy
+
+	visitLimit int
+	history    HistoryStore
+
+	clock Clock
+	idGen IDGenerator
+
+	authorizer  Authorizer
+	breakpoints Breakpoints
 }
 
 // NewPipe creates a new Pipe state for the passed flow name, graph
@@ -30,6 +50,10 @@ func NewPipe[T any](
 		name:  name,
 		graph: graph,
 		store: store,
+		graphs: map[string]Graph[T]{
+			graph.version: graph,
+		},
+		visitLimit: DefaultVisitLimit,
 	}
 }
 
@@ -37,33 +61,89 @@ func NewPipe[T any](
 // during each part of the execution.
 func (p *Pipe[T]) persistStateFunc(ctx context.Context, id string) FlowCallback[T] {
 	return func(cs CheckpointState, runState T) error {
+		cs.UpdatedAt = p.now()
+		cs.Visited = cloneVisitLog(cs.Visited)
+
 		return p.store.Set(ctx, ExecutionID{
 			ID:       id,
 			FlowName: p.name,
 		}, ExecutionState[T]{
 			CheckpointState:  cs,
 			ApplicationState: runState,
+			SchemaVersion:    p.schemaVersion,
 		})
 	}
 }
 
+// compareAndSwap persists the loaded state of an execution only when it wasn't persisted since,
+// see [CompareAndSwapper]. [ErrStoreUnsupported] is returned for stores without the capability.
+func (p *Pipe[T]) compareAndSwap(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	cas, ok := p.store.(CompareAndSwapper[T])
+	if !ok {
+		return ErrStoreUnsupported
+	}
+
+	state.SchemaVersion = p.schemaVersion
+	return cas.CompareAndSwap(ctx, id, state)
+}
+
+// update persists the modified loaded state of an execution. [ErrRevisionConflict] is returned
+// when the execution was persisted since it was loaded, unless the store can't detect it.
+func (p *Pipe[T]) update(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	err := p.compareAndSwap(ctx, id, state)
+	if errors.Is(err, ErrStoreUnsupported) {
+		state.SchemaVersion = p.schemaVersion
+		return p.store.Set(ctx, id, state)
+	}
+
+	return err
+}
+
+// runFunc executes the flow of an execution, see [Pipe.invoke].
+type runFunc[T any] func(
+	ctx context.Context,
+	id string,
+	graph Graph[T],
+	checkpointState CheckpointState,
+	initState T,
+) (T, error)
+
 // invoke is a common function which all the pipe execution functions use to
-// start the flow execution. This takes in a unique identifier, checkpoint
-// of the flow execution and execution's the initial state.
+// start the flow execution. This takes in a unique identifier, graph version to
+// execute, checkpoint of the flow execution and execution's the initial state.
 func (p *Pipe[T]) invoke(
 	ctx context.Context,
 	id string,
+	graph Graph[T],
 	checkpointState CheckpointState,
 	initState T,
 ) (T, error) {
+	if err := p.persistence.validate(); err != nil {
+		return initState, err
+	}
+
+	return p.newFlow(ctx, id, graph, checkpointState).Execute(ctx, initState)
+}
+
+// newFlow creates the flow of an execution with the callbacks persisting the execution state.
+func (p *Pipe[T]) newFlow(
+	ctx context.Context,
+	id string,
+	graph Graph[T],
+	checkpointState CheckpointState,
+) *Flow[T] {
 	storeFunc := p.persistStateFunc(ctx, id)
-	flow := NewFlow(p.name, p.graph).
+	return NewFlow(p.name, graph).
+		WithExecutionID(id).
 		WithCheckpoint(checkpointState).
-		OnNodeExec(storeFunc).
+		WithVisitLimit(p.visitLimit).
+		WithClock(p.clock).
+		WithIDGenerator(p.idGen).
+		WithBreakpoints(p.breakpoints.merge(getBreakpoints(ctx))).
+		OnNodeStart(p.recordVisitFunc(ctx, id)).
+		OnNodeExec(p.nodeExecCallback(graph, storeFunc)).
 		OnInterrupt(storeFunc).
 		OnGraphEnd(storeFunc)
-
-	return flow.Execute(ctx, initState)
 }
 
 // Invoke is used to start a flow execution for a given unique identifier
@@ -73,29 +153,80 @@ func (p *Pipe[T]) Invoke(
 	id string,
 	initState T,
 ) (T, error) {
-	return p.invoke(ctx, id, CheckpointState{
-		Visited:          make([]string, 0),
+	cs := p.newCheckpoint()
+	if err := p.persistence.validate(); err != nil {
+		return initState, err
+	}
+
+	// Without a checkpoint after the first node, the execution couldn't be recovered.
+	if !p.persistence.persistsEveryNode() {
+		if err := p.persistStateFunc(ctx, id)(cs, initState); err != nil {
+			return initState, err
+		}
+	}
+
+	return p.invoke(ctx, id, p.graph, cs, initState)
+}
+
+// newCheckpoint returns the checkpoint state of a new execution.
+func (p *Pipe[T]) newCheckpoint() CheckpointState {
+	return CheckpointState{
+		Visited:          NewVisitLog(),
 		InterruptHistory: make([]ResolvedHITLInterrupt, 0),
-	}, initState)
+		Status:           StatusRunning,
+		CreatedAt:        p.now(),
+	}
 }
 
+// ErrNoPendingInterrupt is returned when continuing an execution which isn't waiting on an interrupt.
+var ErrNoPendingInterrupt = errors.New("execution has no pending interrupt")
+
 // ResumeConfig defines the values required for resuming the flow execution.
 type ResumeConfig struct {
 	// InterruptValues stores answer values provided during the HITL interration
 	// for the HITL Interrupt in the previous execution step.
 	InterruptValues map[string]string
+	// InterruptID identifies the interrupt the values answer. When it's set, answers to an
+	// interrupt other than the pending one are rejected with a [StaleInterruptError], unless
+	// they repeat the values the interrupt was already resolved with.
+	InterruptID InterruptID
+	// StatePatch is a JSON merge patch (RFC 7396) applied to the state before resuming an
+	// execution paused on a breakpoint, see [ApplyMergePatch].
+	StatePatch json.RawMessage
+	// Resolver identifies who is resolving the interrupt. It's passed to the [Authorizer]
+	// of the pipe and recorded in the interrupt history.
+	Resolver Principal
 }
 
 // Continue is used to continue the flow execution right after interrupt. This method fetches
 // the execution state for this flow (flow name) and the provided ID, validates the interrupt
 // values provided against the original interrupt requirements.
+//
+// [ErrExecutionNotFound] is returned for unknown executions and [ErrNoPendingInterrupt]
+// for executions which aren't waiting on an interrupt. The [Authorizer] of the pipe, if any,
+// decides whether the [ResumeConfig.Resolver] may resolve the pending interrupt. When the store
+// implements [CompareAndSwapper], concurrent answers to the same interrupt resume the execution
+// only once, the other answers are handled as answers to an interrupt which isn't pending anymore.
+// The interrupt is pending again when the resumed node fails with an error.
 func (p *Pipe[T]) Continue(
 	ctx context.Context,
 	id string,
 	rc ResumeConfig,
 ) (T, error) {
-	// Get the execution state for the passed ID and flow name.
-	execState, err := p.store.Get(ctx, ExecutionID{
+	return p.continueWith(ctx, id, rc, p.invoke)
+}
+
+// continueWith validates the resume config against the pending interrupt of the execution and
+// resumes it using the run function.
+func (p *Pipe[T]) continueWith(
+	ctx context.Context,
+	id string,
+	rc ResumeConfig,
+	run runFunc[T],
+) (T, error) {
+	// Get the execution state for the passed ID and flow name, upgraded
+	// to the current schema version.
+	execState, err := p.load(ctx, ExecutionID{
 		ID:       id,
 		FlowName: p.name,
 	})
@@ -103,45 +234,199 @@ func (p *Pipe[T]) Continue(
 		return execState.ApplicationState, err
 	}
 
-	// Validate the interrupt values and collect the interrupt values
-	interruptValues := make(map[string]string, len(execState.CheckpointState.Interrupt.Requirements))
-	for key, req := range execState.CheckpointState.Interrupt.Requirements {
-		ans, ok := rc.InterruptValues[key]
-		if !ok {
-			return execState.ApplicationState, RequirementKeyNotFound(key)
+	switch execState.CheckpointState.Status {
+	case StatusExpired:
+		return execState.ApplicationState, ErrExecutionExpired
+	case StatusFailed:
+		return execState.ApplicationState, ErrExecutionFailed
+	}
+
+	// Answers to an interrupt which isn't pending anymore are either duplicates
+	// or stale.
+	if rc.InterruptID != (InterruptID{}) && rc.InterruptID != execState.CheckpointState.Interrupt.InterruptID {
+		return p.replay(ctx, id, execState, rc)
+	}
+
+	// Only interrupted executions can be continued.
+	if execState.CheckpointState.Interrupt.InterruptID.NodeID == "" {
+		return execState.ApplicationState, ErrNoPendingInterrupt
+	}
+
+	// Expired interrupts are resolved by their timeout policy.
+	if execState.CheckpointState.Interrupt.expired(p.now()) {
+		return execState.ApplicationState, ErrInterruptExpired
+	}
+
+	// Resume on the graph version the execution was started with.
+	graph, err := p.graphFor(execState.CheckpointState)
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	if err := p.authorize(ctx, id, execState.CheckpointState.Interrupt, rc.Resolver); err != nil {
+		return execState.ApplicationState, err
+	}
+
+	// Validate the interrupt values against the persisted requirements and
+	// collect the interrupt values in their canonical form.
+	interruptValues, err := execState.CheckpointState.Interrupt.Requirements.Resolve(rc.InterruptValues)
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	// The claim is released with the state as it was loaded, without the state patch.
+	pending := execState
+
+	if len(rc.StatePatch) > 0 {
+		if !execState.CheckpointState.Interrupt.isBreakpoint() {
+			return execState.ApplicationState, ErrStatePatchUnsupported
 		}
 
-		if req.Type == Enum && !slices.Contains(req.Suggestions, ans) {
-			return execState.ApplicationState, RequirementInvalid(key, ans, req.Suggestions)
+		execState.ApplicationState, err = ApplyMergePatch(execState.ApplicationState, rc.StatePatch)
+		if err != nil {
+			return execState.ApplicationState, err
+		}
+	}
+
+	resolved := ResolvedHITLInterrupt{
+		HITLInterrupt: execState.CheckpointState.Interrupt,
+		Values:        interruptValues,
+		Resolution:    ResolutionAnswered,
+		ResolvedBy:    rc.Resolver,
+		ResolvedAt:    p.now(),
+	}
+
+	release, err := p.claim(ctx, id, pending, resolvedState(execState, resolved))
+	if errors.Is(err, ErrRevisionConflict) {
+		// The interrupt was resolved concurrently, the answer is either a duplicate or stale.
+		rc.InterruptID = resolved.InterruptID
+		execState, err = p.load(ctx, ExecutionID{
+			ID:       id,
+			FlowName: p.name,
+		})
+		if err != nil {
+			return execState.ApplicationState, err
 		}
 
-		interruptValues[key] = ans
+		return p.replay(ctx, id, execState, rc)
 	}
 
-	// Load the context with the interrupt values.
-	loadedCtx := LoadInterrupt(
-		ctx,
-		execState.CheckpointState.Interrupt,
-		interruptValues,
-	)
+	if err != nil {
+		return execState.ApplicationState, err
+	}
 
 	// Resume the flow processing with the checkpoint execution state, app state
 	// interrupt values stored in the flow execution context.
-	return p.invoke(loadedCtx, id, execState.CheckpointState, execState.ApplicationState)
+	state, err := run(loadResolvedInterrupt(ctx, resolved), id, graph, execState.CheckpointState, execState.ApplicationState)
+	return state, release(err)
 }
 
-// LoadInterrupt is used to load the context with a resolved interrupt (original HITLInterrupt and answer values).
-func LoadInterrupt(ctx context.Context, interrupt HITLInterrupt, values map[string]string) context.Context {
-	return context.WithValue(ctx, "interrupt_of:"+interrupt.InterruptID.NodeID, ResolvedHITLInterrupt{
-		HITLInterrupt: interrupt,
-		Values:        values,
-	})
+// resolvedState returns the state of an execution with its pending interrupt resolved.
+func resolvedState[T any](execState ExecutionState[T], ri ResolvedHITLInterrupt) ExecutionState[T] {
+	cs := execState.CheckpointState
+	ri.Step = cs.Visited.Len()
+	cs.InterruptHistory = append(slices.Clone(cs.InterruptHistory), ri)
+	cs.Interrupt = HITLInterrupt{}
+	cs.Status = StatusRunning
+
+	execState.CheckpointState = cs
+	return execState
+}
+
+// claim persists the claimed state of an execution with a compare-and-swap on the revision its
+// pending state was loaded at, so that only one of the concurrent resumes of a suspended execution
+// executes the node, the others get [ErrRevisionConflict]. Executions in stores without the
+// [CompareAndSwapper] capability aren't claimed.
+//
+// The returned function must be called with the error of the resumed execution. It restores the
+// pending state when the execution failed with an error other than a suspension, so that it can
+// be resumed again, unless the execution was persisted since it was claimed.
+func (p *Pipe[T]) claim(
+	ctx context.Context,
+	id string,
+	pending ExecutionState[T],
+	claimed ExecutionState[T],
+) (func(error) error, error) {
+	eid := ExecutionID{
+		ID:       id,
+		FlowName: p.name,
+	}
+
+	claimed.CheckpointState.UpdatedAt = p.now()
+	err := p.compareAndSwap(ctx, eid, claimed)
+	if errors.Is(err, ErrStoreUnsupported) {
+		// Wrapping stores only support it when the wrapped store does.
+		return func(err error) error { return err }, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(err error) error {
+		if ignoreSuspension(err) == nil {
+			return err
+		}
+
+		// The claim persisted the revision following the pending one.
+		pending.Revision++
+		rerr := p.compareAndSwap(context.WithoutCancel(ctx), eid, pending)
+		if rerr == nil || errors.Is(rerr, ErrRevisionConflict) {
+			return err
+		}
+
+		return errors.Join(err, fmt.Errorf("releasing the claim: %w", rerr))
+	}, nil
+}
+
+// replay handles the answers to an interrupt which isn't pending anymore. Answers submitted again
+// with the values the interrupt was resolved with return the current state of the execution, along
+// with the pending interrupt if any, as the first submission did. Any other answer is stale.
+func (p *Pipe[T]) replay(ctx context.Context, id string, execState ExecutionState[T], rc ResumeConfig) (T, error) {
+	cs := execState.CheckpointState
+	stale := StaleInterruptError{
+		InterruptID: rc.InterruptID,
+		Pending:     cs.Interrupt.InterruptID,
+	}
+
+	for _, ri := range slices.Backward(cs.InterruptHistory) {
+		if ri.InterruptID != rc.InterruptID {
+			continue
+		}
+
+		if ri.Resolution == ResolutionTimeout {
+			return execState.ApplicationState, stale
+		}
+
+		if err := p.authorize(ctx, id, ri.HITLInterrupt, rc.Resolver); err != nil {
+			return execState.ApplicationState, err
+		}
+
+		values, err := ri.Requirements.Resolve(rc.InterruptValues)
+		if err != nil || !maps.Equal(values, ri.Values) {
+			return execState.ApplicationState, stale
+		}
+
+		if cs.Interrupt.InterruptID.NodeID != "" {
+			return execState.ApplicationState, cs.Interrupt
+		}
+
+		return execState.ApplicationState, nil
+	}
+
+	return execState.ApplicationState, stale
 }
 
-// getLoadedInterrupt is a private function used to just get the loaded resolved interrupt from the context.
-func getLoadedInterrupt(ctx context.Context, interrupt HITLInterrupt) (ResolvedHITLInterrupt, bool) {
-	rint, ok := ctx.Value("interrupt_of:" + interrupt.InterruptID.NodeID).(ResolvedHITLInterrupt)
-	return rint, ok
+// authorize consults the authorizer of the pipe, if any, for the resolver of the interrupt.
+func (p *Pipe[T]) authorize(ctx context.Context, id string, interrupt HITLInterrupt, resolver Principal) error {
+	if p.authorizer == nil {
+		return nil
+	}
+
+	return p.authorizer.Authorize(ctx, ExecutionID{
+		ID:       id,
+		FlowName: p.name,
+	}, interrupt, resolver)
 }
 
 // Interrupt is a helper function which calls [InterruptWithValidation] with a no validation.
@@ -166,8 +451,10 @@ func Interrupt(
 // the previously created HITL interrupt is found with resolved answer from the user.
 //
 // Validation:
-// When the resolved interrupt is found, the values sumbitted by the user is passed through the validation function.
-// Any error returned will be bubbled up as a HITL Interrupt with a validation error attached to it.
+// When the resolved interrupt is found, the values sumbitted by the user are resolved against the passed
+// requirements (see [Requirements.Resolve]), which also runs the requirement validators, and then passed
+// through the validation function. Any error returned will be bubbled up as a HITL Interrupt with a
+// validation error attached to it.
 func InterruptWithValidation(
 	ctx context.Context,
 	message string,
@@ -175,6 +462,52 @@ func InterruptWithValidation(
 	values Requirements,
 	fn func(map[string]string) error,
 ) (map[string]string, error) {
+	return InterruptWithKey(ctx, "", message, reason, values, fn)
+}
+
+// InterruptWithKey is [InterruptWithValidation] for nodes raising several interrupts, each identified
+// by a key unique within the node. Interrupts resolved earlier in the same step are replayed from the
+// interrupt history when the node is resumed, so a node can ask its questions in sequence:
+//
+//	from, err := InterruptWithKey(ctx, "from", "Where from?", "origin", fromReqs, validate)
+//	if err != nil {
+//		return state, err
+//	}
+//
+//	to, err := InterruptWithKey(ctx, "to", "Where to?", "destination", toReqs, validate)
+func InterruptWithKey(
+	ctx context.Context,
+	key string,
+	message string,
+	reason string,
+	values Requirements,
+	fn func(map[string]string) error,
+) (map[string]string, error) {
+	return RequestInterrupt(ctx, HITLInterrupt{
+		Reason:       reason,
+		Message:      message,
+		Requirements: values,
+		InterruptID:  InterruptID{Key: key},
+	}, fn)
+}
+
+// RequestInterrupt returns the validated values of the resolved interrupt of the node loaded in the
+// context or raises the passed interrupt, see [InterruptWithKey]. The interrupt is identified by its
+// [InterruptID.Key], the node ID and ID of the interrupt are set when it's raised, along with the
+// ExpiresAt time of interrupts with a [TimeoutPolicy.After] duration.
+//
+//	values, err := flodk.RequestInterrupt(ctx, flodk.HITLInterrupt{
+//		Message:      "Confirm the booking",
+//		Requirements: reqs,
+//		Timeout:      flodk.TimeoutPolicy{After: 24 * time.Hour, Action: flodk.TimeoutResume},
+//	}, validate)
+func RequestInterrupt(
+	ctx context.Context,
+	interrupt HITLInterrupt,
+	fn func(map[string]string) error,
+) (map[string]string, error) {
+	key, values := interrupt.InterruptID.Key, interrupt.Requirements
+
 	// Get the nodeID from the ctx. If a node is executed by a flow,
 	// this value is always guaranteed to be set.
 	nodeID, ok := GetNodeID(ctx)
@@ -182,30 +515,36 @@ func InterruptWithValidation(
 		// If this method is called using a context which is not
 		// correctly initialized by the flow.Execute method, this error
 		// will occur.
-		return nil, errors.New("nodeID not found in context")
+		return nil, ErrNodeIDNotFound
 	}
 
 	// Get the existing interrupt with resolved values if any.
-	existingInterrupt, ok := ctx.Value("interrupt_of:" + nodeID).(ResolvedHITLInterrupt)
+	existingInterrupt, ok := getLoadedInterrupt(ctx, nodeID, key)
 	if ok {
 		// Validate the values
-		err := fn(existingInterrupt.Values)
+		resolved, err := values.Resolve(existingInterrupt.Values)
+		if err == nil {
+			err = fn(resolved)
+		}
+
 		if err != nil {
-			existingInterrupt.HITLInterrupt.ValidationError = err
+			existingInterrupt.HITLInterrupt.ValidationError = NewValidationError(err)
 			return nil, existingInterrupt.HITLInterrupt
 		}
 		// Return the values.
-		return existingInterrupt.Values, nil
+		return resolved, nil
 	}
 
 	// No HITL found? create a new interrupt.
-	return nil, HITLInterrupt{
-		Reason:       reason,
-		Message:      message,
-		Requirements: values,
-		InterruptID: InterruptID{
-			NodeID: nodeID,
-			ID:     fmt.Sprintf("%x.%x", time.Now().UnixNano(), rand.Int64()),
-		},
+	interrupt.InterruptID = InterruptID{
+		NodeID: nodeID,
+		ID:     GetIDGenerator(ctx).NewID(),
+		Key:    key,
+	}
+
+	if interrupt.Timeout.After > 0 && interrupt.ExpiresAt.IsZero() {
+		interrupt.ExpiresAt = GetClock(ctx).Now().Add(interrupt.Timeout.After)
 	}
+
+	return nil, interrupt
 }
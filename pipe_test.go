@@ -0,0 +1,281 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPipeContinueWithoutInterrupt(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("continue", graph, NewInMemoryStore[State]())
+
+	if _, err := pipe.Continue(t.Context(), "missing", ResumeConfig{}); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("expected ErrExecutionNotFound, got %v", err)
+	}
+
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err != nil {
+		t.Fatalf("error while invoking the flow: %s", err)
+	}
+
+	if _, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{}); !errors.Is(err, ErrNoPendingInterrupt) {
+		t.Errorf("expected ErrNoPendingInterrupt, got %v", err)
+	}
+}
+
+func TestPipeSequentialInterrupts(t *testing.T) {
+	calls := 0
+	graph, err := NewGraphBuilder[State]().
+		AddNode("route", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			calls++
+			reqs := Requirements{"airport": {Type: Custom}}
+
+			from, err := InterruptWithKey(ctx, "from", "Where from?", "origin", reqs, func(map[string]string) error { return nil })
+			if err != nil {
+				return state, err
+			}
+
+			to, err := InterruptWithKey(ctx, "to", "Where to?", "destination", reqs, func(map[string]string) error { return nil })
+			if err != nil {
+				return state, err
+			}
+
+			if from["airport"] != "MAA" || to["airport"] != "BLR" {
+				t.Errorf("unexpected answers: %v, %v", from, to)
+			}
+
+			state.sum++
+			return state, nil
+		})).
+		SetStartNode("route").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("sequential", graph, store)
+
+	var interrupt HITLInterrupt
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); !errors.As(err, &interrupt) || interrupt.InterruptID.Key != "from" {
+		t.Fatalf("expected the from interrupt, got %v", err)
+	}
+
+	_, err = pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"airport": "MAA"}})
+	if !errors.As(err, &interrupt) || interrupt.InterruptID.Key != "to" {
+		t.Fatalf("expected the to interrupt, got %v", err)
+	}
+
+	state, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"airport": "BLR"}})
+	if err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	if state.sum != 1 || calls != 3 {
+		t.Errorf("expected the node to complete on the third call, got sum %d after %d calls", state.sum, calls)
+	}
+
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "sequential"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history := es.CheckpointState.InterruptHistory
+	if len(history) != 2 || history[0].InterruptID.Key != "from" || history[1].InterruptID.Key != "to" || history[1].Step != 1 {
+		t.Errorf("unexpected interrupt history: %+v", history)
+	}
+}
+
+func TestPipeContinueStaleInterrupt(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			for _, key := range []string{"first", "second"} {
+				if _, err := InterruptWithKey(ctx, key, "Continue?", key, Requirements{"ok": {Type: Boolean}}, func(map[string]string) error { return nil }); err != nil {
+					return state, err
+				}
+			}
+
+			state.sum++
+			return state, nil
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("stale", graph, NewInMemoryStore[State]()).WithIDGenerator(NewSequentialIDs("i-"))
+	answer := func(id InterruptID, value string) (State, error) {
+		return pipe.Continue(t.Context(), "thread-1", ResumeConfig{
+			InterruptID:     id,
+			InterruptValues: map[string]string{"ok": value},
+		})
+	}
+
+	var first, second HITLInterrupt
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); !errors.As(err, &first) {
+		t.Fatalf("expected interrupt, got %v", err)
+	}
+
+	if _, err := answer(first.InterruptID, "yes"); !errors.As(err, &second) || second.InterruptID.Key != "second" {
+		t.Fatalf("expected the second interrupt, got %v", err)
+	}
+
+	// The same answer submitted twice returns the pending interrupt again.
+	var pending HITLInterrupt
+	if _, err := answer(first.InterruptID, "y"); !errors.As(err, &pending) || pending.InterruptID != second.InterruptID {
+		t.Errorf("expected the pending interrupt for a duplicate answer, got %v", err)
+	}
+
+	var stale StaleInterruptError
+	if _, err := answer(first.InterruptID, "no"); !errors.As(err, &stale) || stale.Pending != second.InterruptID {
+		t.Errorf("expected StaleInterruptError for a different answer, got %v", err)
+	}
+
+	if _, err := answer(InterruptID{NodeID: "ask", ID: "unknown"}, "yes"); !errors.As(err, &stale) {
+		t.Errorf("expected StaleInterruptError for an unknown interrupt, got %v", err)
+	}
+
+	state, err := answer(second.InterruptID, "yes")
+	if err != nil || state.sum != 1 {
+		t.Fatalf("expected the flow to complete, got %v with sum %d", err, state.sum)
+	}
+
+	if state, err := answer(second.InterruptID, "yes"); err != nil || state.sum != 1 {
+		t.Errorf("expected the completed state for a duplicate answer, got %v with sum %d", err, state.sum)
+	}
+}
+
+// racingStore resumes an execution concurrently right before the first compare-and-swap.
+type racingStore[T any] struct {
+	*InMemoryStore[T]
+	race func()
+}
+
+func (s *racingStore[T]) CompareAndSwap(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+
+	return s.InMemoryStore.CompareAndSwap(ctx, id, state)
+}
+
+func TestPipeContinueConcurrently(t *testing.T) {
+	executed := 0
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			if _, err := Interrupt(ctx, "Continue?", "confirm", Requirements{"ok": {Type: Boolean}}); err != nil {
+				return state, err
+			}
+
+			executed++
+			state.sum++
+			return state, nil
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := &racingStore[State]{InMemoryStore: NewInMemoryStore[State]()}
+	pipe := NewPipe("race", graph, store)
+	answer := func(value string) (State, error) {
+		return pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"ok": value}})
+	}
+
+	for _, tc := range []struct {
+		name  string
+		value string
+		stale bool
+	}{
+		{name: "duplicate", value: "yes"},
+		{name: "stale", value: "no", stale: true},
+	} {
+		executed = 0
+		if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+			t.Fatal("expected the flow to be interrupted")
+		}
+
+		store.race = func() {
+			if _, err := answer("yes"); err != nil {
+				t.Errorf("error while continuing the flow: %s", err)
+			}
+		}
+
+		state, err := answer(tc.value)
+
+		var stale StaleInterruptError
+		if tc.stale != errors.As(err, &stale) || (!tc.stale && err != nil) {
+			t.Errorf("%s: unexpected error for the concurrent answer: %v", tc.name, err)
+		}
+
+		if !tc.stale && state.sum != 1 {
+			t.Errorf("%s: expected the completed state for the duplicate answer, got sum %d", tc.name, state.sum)
+		}
+
+		if executed != 1 {
+			t.Errorf("%s: expected the node to complete once, got %d", tc.name, executed)
+		}
+	}
+}
+
+func TestPipeContinueNodeError(t *testing.T) {
+	fail := true
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			if _, err := Interrupt(ctx, "Continue?", "confirm", Requirements{"ok": {Type: Boolean}}); err != nil {
+				return state, err
+			}
+
+			if fail {
+				return state, errors.New("node failed")
+			}
+
+			state.sum++
+			return state, nil
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("retry", graph, store)
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the flow to be interrupted")
+	}
+
+	rc := ResumeConfig{InterruptValues: map[string]string{"ok": "yes"}}
+	if _, err := pipe.Continue(t.Context(), "thread-1", rc); err == nil || err.Error() != "node failed" {
+		t.Fatalf("expected the node error, got %v", err)
+	}
+
+	execState, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "retry"})
+	if err != nil {
+		t.Fatalf("error while getting the execution: %s", err)
+	}
+
+	if cs := execState.CheckpointState; cs.Status != StatusInterrupted || cs.Interrupt.InterruptID.NodeID == "" || len(cs.InterruptHistory) != 0 {
+		t.Errorf("expected the interrupt to be pending again, got %+v", cs)
+	}
+
+	fail = false
+	state, err := pipe.Continue(t.Context(), "thread-1", rc)
+	if err != nil {
+		t.Fatalf("error while continuing the flow again: %s", err)
+	}
+
+	if state.sum != 1 {
+		t.Errorf("expected the node to complete, got sum %d", state.sum)
+	}
+}
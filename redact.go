@@ -0,0 +1,259 @@
+package flodk
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RedactedValue is the value string fields marked for redaction are masked with.
+// Fields of any other type are reset to their zero value.
+const RedactedValue = "[REDACTED]"
+
+// Redactor masks the sensitive data of the application state before it leaves the
+// primary store, for example into audit logs or exported histories. A redactor must
+// not modify the passed state as it is still used by the running nodes.
+type Redactor[T any] func(state T) T
+
+// RedactFields is the default [Redactor] which masks all the fields of the state tagged
+// with `flodk:"redact"`. Nested structs, pointers, slices and maps are followed.
+//
+// Say a state struct is defined as the following:
+//
+//	type ExampleState struct {
+//	    Prompt     string
+//	    Username   string  `flodk:"redact"`
+//	}
+//
+// The Username of the returned state will be set to [RedactedValue].
+func RedactFields[T any](state T) T {
+	val := reflect.ValueOf(&state).Elem()
+	if !needsRedaction(val.Type()) {
+		return state
+	}
+
+	redacted := redactValue(val)
+
+	return redacted.Interface().(T)
+}
+
+// redactTag reports whether the struct field is tagged for redaction.
+func redactTag(field reflect.StructField) bool {
+	return slices.Contains(strings.Split(field.Tag.Get("flodk"), ","), "redact")
+}
+
+// redactableTypes caches whether a type contains fields tagged for redaction.
+var redactableTypes sync.Map
+
+// needsRedaction reports whether the type contains any field tagged for redaction.
+func needsRedaction(typ reflect.Type) bool {
+	if cached, ok := redactableTypes.Load(typ); ok {
+		return cached.(bool)
+	}
+
+	needs := containsRedaction(typ, map[reflect.Type]bool{})
+	redactableTypes.Store(typ, needs)
+
+	return needs
+}
+
+// containsRedaction walks the type to find fields tagged for redaction. Types which are
+// already being visited are skipped to stop the recursion on self referencing types.
+func containsRedaction(typ reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[typ] {
+		return false
+	}
+	visiting[typ] = true
+
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return containsRedaction(typ.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.IsExported() && (redactTag(field) || containsRedaction(field.Type, visiting)) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// redactValue returns a redacted copy of the passed value. Values which don't contain
+// any fields tagged for redaction are shared with the original value.
+func redactValue(val reflect.Value) reflect.Value {
+	typ := val.Type()
+	if !needsRedaction(typ) {
+		return val
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		if val.IsNil() {
+			return val
+		}
+
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(redactValue(val.Elem()))
+		return ptr
+	case reflect.Slice:
+		if val.IsNil() {
+			return val
+		}
+
+		slice := reflect.MakeSlice(typ, val.Len(), val.Len())
+		for i := 0; i < val.Len(); i++ {
+			slice.Index(i).Set(redactValue(val.Index(i)))
+		}
+		return slice
+	case reflect.Array:
+		array := reflect.New(typ).Elem()
+		for i := 0; i < val.Len(); i++ {
+			array.Index(i).Set(redactValue(val.Index(i)))
+		}
+		return array
+	case reflect.Map:
+		if val.IsNil() {
+			return val
+		}
+
+		m := reflect.MakeMapWithSize(typ, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), redactValue(iter.Value()))
+		}
+		return m
+	case reflect.Struct:
+		copied := reflect.New(typ).Elem()
+		copied.Set(val)
+
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldVal := copied.Field(i)
+			if redactTag(field) {
+				if field.Type.Kind() == reflect.String && !fieldVal.IsZero() {
+					fieldVal.SetString(RedactedValue)
+				} else {
+					fieldVal.SetZero()
+				}
+				continue
+			}
+
+			fieldVal.Set(redactValue(val.Field(i)))
+		}
+		return copied
+	}
+
+	return val
+}
+
+// RedactCheckpoint returns a copy of the checkpoint state with the data submitted by users
+// and other systems masked: the answer values of resolved interrupts and validation errors
+// are set to [RedactedValue], the interrupt data and the signal payloads are removed.
+func RedactCheckpoint(cs CheckpointState) CheckpointState {
+	cs.Interrupt = redactInterrupt(cs.Interrupt)
+
+	cs.InterruptHistory = slices.Clone(cs.InterruptHistory)
+	for i, ri := range cs.InterruptHistory {
+		ri.HITLInterrupt = redactInterrupt(ri.HITLInterrupt)
+		if ri.Values != nil {
+			values := make(map[string]string, len(ri.Values))
+			for key := range ri.Values {
+				values[key] = RedactedValue
+			}
+			ri.Values = values
+		}
+
+		cs.InterruptHistory[i] = ri
+	}
+
+	cs.SignalHistory = slices.Clone(cs.SignalHistory)
+	for i := range cs.SignalHistory {
+		cs.SignalHistory[i].Payload = nil
+	}
+
+	return cs
+}
+
+// redactInterrupt masks the data and the rejected values of the interrupt.
+func redactInterrupt(hi HITLInterrupt) HITLInterrupt {
+	hi.Data = nil
+	if hi.ValidationError == nil {
+		return hi
+	}
+
+	ve := *hi.ValidationError
+	ve.Fields = slices.Clone(ve.Fields)
+	for i, field := range ve.Fields {
+		if field.Value == "" {
+			continue
+		}
+
+		// The messages of invalid values quote the value.
+		ve.Message = strings.ReplaceAll(ve.Message, field.Value, RedactedValue)
+		field.Message = strings.ReplaceAll(field.Message, field.Value, RedactedValue)
+		field.Value = RedactedValue
+		ve.Fields[i] = field
+	}
+	hi.ValidationError = &ve
+
+	return hi
+}
+
+// WithRedactor sets the [Redactor] used by [Pipe.Redact]. This replaces the default
+// [RedactFields] redactor, call it from the custom redactor to keep masking the tagged fields.
+func (p *Pipe[T]) WithRedactor(r Redactor[T]) *Pipe[T] {
+	p.redactor = r
+
+	return p
+}
+
+// Redact returns a copy of the application state with the sensitive data masked
+// by the redactor of the pipe.
+func (p *Pipe[T]) Redact(state T) T {
+	if p.redactor == nil {
+		return RedactFields(state)
+	}
+
+	return p.redactor(state)
+}
+
+// RedactingStore wraps a secondary [Store], like an audit log, so that all the states
+// persisted into it are redacted, see [RedactCheckpoint]. The primary store of a pipe must not be wrapped,
+// as the nodes need the real values when the execution is resumed.
+type RedactingStore[T any] struct {
+	inner    Store[T]
+	redactor Redactor[T]
+}
+
+// NewRedactingStore creates a new [RedactingStore]. When the redactor is nil, [RedactFields] is used.
+func NewRedactingStore[T any](inner Store[T], redactor Redactor[T]) *RedactingStore[T] {
+	if redactor == nil {
+		redactor = RedactFields[T]
+	}
+
+	return &RedactingStore[T]{
+		inner:    inner,
+		redactor: redactor,
+	}
+}
+
+// Get implements the [Store.Get] method of the [Store] interface.
+func (s *RedactingStore[T]) Get(ctx context.Context, id ExecutionID) (ExecutionState[T], error) {
+	return s.inner.Get(ctx, id)
+}
+
+// Set implements the [Store.Set] method of the [Store] interface.
+func (s *RedactingStore[T]) Set(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	state.CheckpointState = RedactCheckpoint(state.CheckpointState)
+	state.ApplicationState = s.redactor(state.ApplicationState)
+
+	return s.inner.Set(ctx, id, state)
+}
@@ -0,0 +1,86 @@
+package flodk
+
+import (
+	"strings"
+	"testing"
+)
+
+type passenger struct {
+	Name    string `json:"name" flodk:"redact"`
+	Seat    string `json:"seat"`
+	Age     int    `json:"age" flodk:"redact"`
+	Contact *struct {
+		Email string `json:"email" flodk:"redact"`
+	} `json:"contact"`
+}
+
+type bookingState struct {
+	Prompt     string      `json:"prompt"`
+	Passengers []passenger `json:"passengers"`
+	Lead       passenger   `json:"lead"`
+}
+
+func TestRedactFields(t *testing.T) {
+	state := bookingState{
+		Prompt: "book a flight",
+		Passengers: []passenger{
+			{Name: "Jane Doe", Seat: "12A", Age: 34, Contact: &struct {
+				Email string `json:"email" flodk:"redact"`
+			}{Email: "jane@example.com"}},
+		},
+		Lead: passenger{Name: "John Doe", Seat: "12B", Age: 36},
+	}
+
+	redacted := RedactFields(state)
+
+	if redacted.Prompt != state.Prompt || redacted.Passengers[0].Seat != "12A" || redacted.Lead.Seat != "12B" {
+		t.Errorf("expected untagged fields to be kept, got %+v", redacted)
+	}
+
+	if redacted.Lead.Name != RedactedValue || redacted.Lead.Age != 0 {
+		t.Errorf("expected tagged fields to be masked, got %+v", redacted.Lead)
+	}
+
+	p := redacted.Passengers[0]
+	if p.Name != RedactedValue || p.Age != 0 || p.Contact.Email != RedactedValue {
+		t.Errorf("expected nested tagged fields to be masked, got %+v", p)
+	}
+
+	// The original state must not be modified.
+	if state.Passengers[0].Name != "Jane Doe" || state.Passengers[0].Contact.Email != "jane@example.com" {
+		t.Errorf("expected the original state to be untouched, got %+v", state.Passengers[0])
+	}
+}
+
+func TestRedactCheckpoint(t *testing.T) {
+	cs := CheckpointState{
+		Interrupt: HITLInterrupt{
+			Data: []byte(`{"name":"Jane Doe"}`),
+			ValidationError: NewValidationError(ErrRequirementInvalidValue{
+				Key: "phone", Value: "+1 555 0199", Reason: "unknown number",
+			}),
+		},
+		InterruptHistory: []ResolvedHITLInterrupt{{Values: map[string]string{"phone": "+1 555 0100"}}},
+		SignalHistory:    []ReceivedSignal{{Payload: []byte(`{"card":"4111"}`)}},
+	}
+
+	redacted := RedactCheckpoint(cs)
+
+	if redacted.InterruptHistory[0].Values["phone"] != RedactedValue {
+		t.Errorf("expected the answer values to be masked, got %v", redacted.InterruptHistory[0].Values)
+	}
+
+	field := redacted.Interrupt.ValidationError.Fields[0]
+	if field.Value != RedactedValue || strings.Contains(field.Message, "555") ||
+		strings.Contains(redacted.Interrupt.ValidationError.Message, "555") {
+		t.Errorf("expected the rejected value to be masked, got %+v", redacted.Interrupt.ValidationError)
+	}
+
+	if redacted.Interrupt.Data != nil || redacted.SignalHistory[0].Payload != nil {
+		t.Errorf("expected the interrupt data and signal payloads to be removed")
+	}
+
+	if cs.InterruptHistory[0].Values["phone"] != "+1 555 0100" || cs.Interrupt.ValidationError.Fields[0].Value != "+1 555 0199" {
+		t.Errorf("expected the passed checkpoint state to be left untouched")
+	}
+}
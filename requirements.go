@@ -0,0 +1,293 @@
+package flodk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bound is a helper which returns a pointer to the passed value, used to set the
+// [Requirement.Min] and [Requirement.Max] bounds.
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// Coerce validates the value submitted for the requirement key and returns it in its canonical
+// form: integers and decimals without extra formatting, booleans as `true` or `false`, date/times
+// in [time.RFC3339], multi-select choices as a JSON array and JSON documents compacted.
+// Use the [Values] accessors to read the canonical values.
+func (r Requirement) Coerce(key string, value string) (string, error) {
+	switch r.Type {
+	case Enum:
+		if !slices.Contains(r.Suggestions, value) {
+			return "", RequirementInvalid(key, value, r.Suggestions)
+		}
+
+		return value, nil
+	case CustomWithSuggestions:
+		for _, suggestion := range r.Suggestions {
+			if strings.EqualFold(strings.TrimSpace(value), suggestion) {
+				return suggestion, nil
+			}
+		}
+
+		return value, nil
+	case Integer:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return "", RequirementInvalidReason(key, value, "must be an integer")
+		}
+
+		if err := r.checkBounds(key, value, float64(n)); err != nil {
+			return "", err
+		}
+
+		return strconv.FormatInt(n, 10), nil
+	case Decimal:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", RequirementInvalidReason(key, value, "must be a decimal number")
+		}
+
+		if err := r.checkBounds(key, value, f); err != nil {
+			return "", err
+		}
+
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case Boolean:
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "true", "t", "yes", "y", "on", "1":
+			return "true", nil
+		case "false", "f", "no", "n", "off", "0":
+			return "false", nil
+		}
+
+		return "", RequirementInvalidReason(key, value, "must be a boolean")
+	case DateTime:
+		layout := r.Format
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		// Values already coerced into RFC3339 are accepted as well, as resumed
+		// nodes resolve the persisted values again.
+		t, err := time.Parse(layout, strings.TrimSpace(value))
+		if err != nil {
+			t, err = time.Parse(time.RFC3339, strings.TrimSpace(value))
+		}
+
+		if err != nil {
+			return "", RequirementInvalidReason(key, value, "must be a date/time formatted as "+layout)
+		}
+
+		return t.Format(time.RFC3339), nil
+	case Pattern:
+		re, err := regexp.Compile("^(?:" + r.Pattern + ")$")
+		if err != nil {
+			return "", fmt.Errorf("invalid pattern for %s: %w", key, err)
+		}
+
+		if !re.MatchString(value) {
+			return "", RequirementInvalidReason(key, value, "must match the pattern "+r.Pattern)
+		}
+
+		return value, nil
+	case MultiSelect:
+		choices, err := parseChoices(value)
+		if err != nil {
+			return "", RequirementInvalidReason(key, value, "must be a list of choices")
+		}
+
+		for _, choice := range choices {
+			if !slices.Contains(r.Suggestions, choice) {
+				return "", RequirementInvalid(key, choice, r.Suggestions)
+			}
+		}
+
+		if err := r.checkBounds(key, value, float64(len(choices))); err != nil {
+			return "", err
+		}
+
+		bs, err := json.Marshal(choices)
+		return string(bs), err
+	case JSON:
+		buf := bytes.Buffer{}
+		if err := json.Compact(&buf, []byte(value)); err != nil {
+			return "", RequirementInvalidReason(key, value, "must be valid JSON")
+		}
+
+		return buf.String(), nil
+	}
+
+	return value, nil
+}
+
+// Resolve validates the submitted values against all the requirements and returns the values
+// coerced into their canonical form (see [Requirement.Coerce]) with the defaults applied.
+// Empty optional requirements without a default are left out. Values of unknown keys are dropped.
+//
+// All the failing requirements are reported at once as a [*ValidationError].
+func (r Requirements) Resolve(values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(r))
+	fields := []FieldError{}
+
+	for _, key := range slices.Sorted(maps.Keys(r)) {
+		req := r[key]
+
+		value := values[key]
+		if value == "" {
+			value = req.Default
+		}
+
+		if value == "" {
+			if !req.Optional {
+				fields = append(fields, fieldErrors(RequirementKeyNotFound(key))...)
+			}
+			continue
+		}
+
+		coerced, err := req.Coerce(key, value)
+		if err == nil && req.Validator != nil {
+			err = req.Validator(coerced)
+		}
+
+		if err != nil {
+			for _, fe := range fieldErrors(err) {
+				if fe.Key == "" {
+					fe.Key = key
+				}
+				fields = append(fields, fe)
+			}
+			continue
+		}
+
+		resolved[key] = coerced
+	}
+
+	if len(fields) > 0 {
+		return resolved, newFieldsValidationError(fields)
+	}
+
+	return resolved, nil
+}
+
+// checkBounds checks the passed number against the Min and Max bounds of the requirement.
+func (r Requirement) checkBounds(key string, value string, n float64) error {
+	if r.Min != nil && n < *r.Min {
+		return RequirementInvalidReason(key, value, "must be at least "+strconv.FormatFloat(*r.Min, 'f', -1, 64))
+	}
+
+	if r.Max != nil && n > *r.Max {
+		return RequirementInvalidReason(key, value, "must be at most "+strconv.FormatFloat(*r.Max, 'f', -1, 64))
+	}
+
+	return nil
+}
+
+// parseChoices parses the multi-select choices from a JSON array or comma separated text.
+func parseChoices(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	choices := []string{}
+
+	if strings.HasPrefix(value, "[") {
+		err := json.Unmarshal([]byte(value), &choices)
+		return choices, err
+	}
+
+	for choice := range strings.SplitSeq(value, ",") {
+		if choice = strings.TrimSpace(choice); choice != "" && !slices.Contains(choices, choice) {
+			choices = append(choices, choice)
+		}
+	}
+
+	return choices, nil
+}
+
+// Values is a helper type to read the typed interrupt values returned by [Interrupt]
+// and [InterruptWithValidation]. The values are expected in the canonical form produced
+// by [Requirement.Coerce].
+//
+//	values, err := flodk.Interrupt(ctx, message, reason, requirements)
+//	passengers, err := flodk.Values(values).Int("passengers")
+type Values map[string]string
+
+// get returns the raw value of the key.
+func (v Values) get(key string) (string, error) {
+	value, ok := v[key]
+	if !ok {
+		return "", RequirementKeyNotFound(key)
+	}
+
+	return value, nil
+}
+
+// String returns the value of the key, or an empty string if the key is not set.
+func (v Values) String(key string) string {
+	return v[key]
+}
+
+// Int returns the value of an [Integer] requirement.
+func (v Values) Int(key string) (int64, error) {
+	value, err := v.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// Float returns the value of a [Decimal] or [Integer] requirement.
+func (v Values) Float(key string) (float64, error) {
+	value, err := v.get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// Bool returns the value of a [Boolean] requirement.
+func (v Values) Bool(key string) (bool, error) {
+	value, err := v.get(key)
+	if err != nil {
+		return false, err
+	}
+
+	return strconv.ParseBool(value)
+}
+
+// Time returns the value of a [DateTime] requirement.
+func (v Values) Time(key string) (time.Time, error) {
+	value, err := v.get(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// List returns the choices of a [MultiSelect] requirement.
+func (v Values) List(key string) ([]string, error) {
+	value, err := v.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseChoices(value)
+}
+
+// JSON decodes the value of a [JSON] requirement into dst.
+func (v Values) JSON(key string, dst any) error {
+	value, err := v.get(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal([]byte(value), dst)
+}
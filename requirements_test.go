@@ -0,0 +1,188 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRequirementCoerce(t *testing.T) {
+	tests := map[string]struct {
+		req     Requirement
+		value   string
+		want    string
+		invalid bool
+	}{
+		"enum":                {req: Requirement{Type: Enum, Suggestions: []string{"economy", "business"}}, value: "economy", want: "economy"},
+		"enum_invalid":        {req: Requirement{Type: Enum, Suggestions: []string{"economy", "business"}}, value: "first", invalid: true},
+		"integer":             {req: Requirement{Type: Integer, Min: Bound(1), Max: Bound(9)}, value: " 03 ", want: "3"},
+		"integer_bounds":      {req: Requirement{Type: Integer, Min: Bound(1), Max: Bound(9)}, value: "12", invalid: true},
+		"integer_invalid":     {req: Requirement{Type: Integer}, value: "two", invalid: true},
+		"decimal":             {req: Requirement{Type: Decimal, Max: Bound(100)}, value: "12.50", want: "12.5"},
+		"boolean":             {req: Requirement{Type: Boolean}, value: "Yes", want: "true"},
+		"boolean_invalid":     {req: Requirement{Type: Boolean}, value: "maybe", invalid: true},
+		"datetime":            {req: Requirement{Type: DateTime, Format: time.DateOnly}, value: "2026-05-01", want: "2026-05-01T00:00:00Z"},
+		"datetime_invalid":    {req: Requirement{Type: DateTime, Format: time.DateOnly}, value: "01/05/2026", invalid: true},
+		"pattern":             {req: Requirement{Type: Pattern, Pattern: `[A-Z]{3}`}, value: "MAA", want: "MAA"},
+		"pattern_partial":     {req: Requirement{Type: Pattern, Pattern: `[A-Z]{3}`}, value: "MAAS", invalid: true},
+		"multi_select":        {req: Requirement{Type: MultiSelect, Suggestions: []string{"meal", "bag", "seat"}}, value: "meal, seat", want: `["meal","seat"]`},
+		"multi_select_json":   {req: Requirement{Type: MultiSelect, Suggestions: []string{"meal", "bag"}}, value: `["bag"]`, want: `["bag"]`},
+		"multi_select_choice": {req: Requirement{Type: MultiSelect, Suggestions: []string{"meal", "bag"}}, value: "meal, lounge", invalid: true},
+		"multi_select_max":    {req: Requirement{Type: MultiSelect, Suggestions: []string{"meal", "bag"}, Max: Bound(1)}, value: "meal,bag", invalid: true},
+		"json":                {req: Requirement{Type: JSON}, value: `{ "a": [1, 2] }`, want: `{"a":[1,2]}`},
+		"json_invalid":        {req: Requirement{Type: JSON}, value: `{"a":`, invalid: true},
+		"custom":              {req: Requirement{Type: Custom}, value: "anything", want: "anything"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.req.Coerce("key", tc.value)
+			if tc.invalid {
+				var invalid ErrRequirementInvalidValue
+				if !errors.As(err, &invalid) {
+					t.Errorf("expected ErrRequirementInvalidValue, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	values := Values{
+		"passengers": "3",
+		"fare":       "12.5",
+		"return":     "true",
+		"date":       "2026-05-01T00:00:00Z",
+		"extras":     `["meal","seat"]`,
+		"meta":       `{"a":1}`,
+	}
+
+	if n, err := values.Int("passengers"); err != nil || n != 3 {
+		t.Errorf("expected 3 passengers, got %d, %v", n, err)
+	}
+
+	if f, err := values.Float("fare"); err != nil || f != 12.5 {
+		t.Errorf("expected fare 12.5, got %f, %v", f, err)
+	}
+
+	if b, err := values.Bool("return"); err != nil || !b {
+		t.Errorf("expected return true, got %t, %v", b, err)
+	}
+
+	if d, err := values.Time("date"); err != nil || !d.Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected date %s, %v", d, err)
+	}
+
+	if l, err := values.List("extras"); err != nil || !slices.Equal(l, []string{"meal", "seat"}) {
+		t.Errorf("unexpected extras %v, %v", l, err)
+	}
+
+	meta := map[string]int{}
+	if err := values.JSON("meta", &meta); err != nil || meta["a"] != 1 {
+		t.Errorf("unexpected meta %v, %v", meta, err)
+	}
+
+	var notFound ErrRequirmentKeyNotFound
+	if _, err := values.Int("missing"); !errors.As(err, &notFound) {
+		t.Errorf("expected ErrRequirmentKeyNotFound, got %v", err)
+	}
+}
+
+func TestRequirementsResolve(t *testing.T) {
+	reqs := Requirements{
+		"origin":      {Type: Custom},
+		"destination": {Type: Custom},
+		"class":       {Type: CustomWithSuggestions, Suggestions: []string{"Economy", "Business"}},
+		"passengers":  {Type: Integer, Default: "1"},
+		"notes":       {Type: Custom, Optional: true},
+		"code": {Type: Pattern, Pattern: `[A-Z]{3}`, Validator: func(value string) error {
+			if value == "XXX" {
+				return errors.New("unknown airport")
+			}
+			return nil
+		}},
+	}
+
+	resolved, err := reqs.Resolve(map[string]string{
+		"origin":      "MAA",
+		"destination": "DEL",
+		"class":       "economy",
+		"code":        "BLR",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := map[string]string{"origin": "MAA", "destination": "DEL", "class": "Economy", "passengers": "1", "code": "BLR"}
+	if len(resolved) != len(want) {
+		t.Errorf("expected %v, got %v", want, resolved)
+	}
+	for k, v := range want {
+		if resolved[k] != v {
+			t.Errorf("expected %s=%q, got %q", k, v, resolved[k])
+		}
+	}
+
+	_, err = reqs.Resolve(map[string]string{"passengers": "many", "code": "XXX"})
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	keys := []string{}
+	for _, fe := range ve.Fields {
+		keys = append(keys, fe.Key)
+	}
+
+	if want := []string{"class", "code", "destination", "origin", "passengers"}; !slices.Equal(keys, want) {
+		t.Errorf("expected field errors for %v, got %v", want, keys)
+	}
+}
+
+func TestPipeResumeDateTimeFormat(t *testing.T) {
+	var departure time.Time
+	graph, err := NewGraphBuilder[State]().
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			values, err := Interrupt(ctx, "When?", "departure_not_found", Requirements{
+				"date": {Type: DateTime, Format: "02/01/2006"},
+			})
+			if err != nil {
+				return state, err
+			}
+
+			departure, err = Values(values).Time("date")
+			return state, err
+		})).
+		SetStartNode("ask").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("departure", graph, NewInMemoryStore[State]())
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the flow to be interrupted")
+	}
+
+	if _, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{
+		InterruptValues: map[string]string{"date": "24/12/2026"},
+	}); err != nil {
+		t.Fatalf("error while continuing the flow: %s", err)
+	}
+
+	if want := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC); !departure.Equal(want) {
+		t.Errorf("expected %s, got %s", want, departure)
+	}
+}
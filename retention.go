@@ -0,0 +1,266 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrExecutionExpired is returned when resuming an execution which was marked as expired
+	// by the [RetentionPolicy] of the pipe.
+	ErrExecutionExpired = errors.New("execution expired")
+	// ErrInvalidRetentionPolicy is returned when sweeping a pipe with an invalid [RetentionPolicy].
+	ErrInvalidRetentionPolicy = errors.New("invalid retention policy")
+	// ErrInvalidInterval is returned when running a [Janitor] or a [Scheduler] with an interval
+	// which isn't positive.
+	ErrInvalidInterval = errors.New("interval must be positive")
+)
+
+// RetentionPolicy defines how long the executions of a pipe are kept in the store.
+// A zero duration disables the respective rule.
+type RetentionPolicy[T any] struct {
+	// KeepCompleted is the duration completed executions are kept after their last update.
+	KeepCompleted time.Duration
+	// ExpireInterrupted is the duration after which an interrupted execution, which wasn't
+	// resumed, is marked as expired.
+	ExpireInterrupted time.Duration
+	// ExpireRunning is the duration after which a running execution, which wasn't updated,
+	// is considered abandoned and marked as expired. Running executions are only updated
+	// after every node with [PersistEveryNode], other persistence modes are rejected
+	// with [ErrInvalidRetentionPolicy].
+	ExpireRunning time.Duration
+	// ExpireWaiting is the duration after which an execution waiting on a signal, which
+	// wasn't delivered, is marked as expired.
+	ExpireWaiting time.Duration
+	// ExpireSleeping is the duration after which a sleeping execution, which wasn't woken up
+	// once its timer was due, is marked as expired.
+	ExpireSleeping time.Duration
+	// KeepExpired is the duration expired executions are kept after they were marked as expired.
+	KeepExpired time.Duration
+	// OnExpire is called after an execution is marked as expired.
+	OnExpire func(ctx context.Context, id ExecutionID, state ExecutionState[T]) error
+}
+
+// WithRetention sets the retention policy enforced by [Pipe.Sweep].
+func (p *Pipe[T]) WithRetention(policy RetentionPolicy[T]) *Pipe[T] {
+	p.retention = policy
+
+	return p
+}
+
+// SweepReport summarizes the executions affected by a sweep.
+type SweepReport struct {
+	Expired int
+	Deleted int
+}
+
+// Add adds the counts of the passed report to this report.
+func (sr *SweepReport) Add(other SweepReport) {
+	sr.Expired += other.Expired
+	sr.Deleted += other.Deleted
+}
+
+// forEachExecution calls the passed function for every persisted execution of this pipe.
+// Executions which can't be loaded are skipped and their errors are joined. The store must
+// implement the [Lister] interface.
+func (p *Pipe[T]) forEachExecution(
+	ctx context.Context,
+	fn func(id ExecutionID, state ExecutionState[T]) error,
+) error {
+	lister, ok := p.store.(Lister)
+	if !ok {
+		return ErrStoreUnsupported
+	}
+
+	ids, err := lister.List(ctx, p.name)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
+		state, err := p.load(ctx, id)
+		if errors.Is(err, ErrExecutionNotFound) {
+			// Deleted since it was listed.
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("execution %s: %w", id.ID, err))
+			continue
+		}
+
+		if err := fn(id, state); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Sweep enforces the retention policy of the pipe once. Interrupted, waiting, sleeping and
+// abandoned running executions past their expiry are marked as expired, completed and expired
+// executions past their retention are deleted. The store must implement both
+// the [Lister] and [Deleter] interfaces.
+func (p *Pipe[T]) Sweep(ctx context.Context) (SweepReport, error) {
+	report := SweepReport{}
+
+	if p.retention.ExpireRunning > 0 && !p.persistence.persistsEveryNode() {
+		return report, fmt.Errorf("%w: ExpireRunning requires persisting every node", ErrInvalidRetentionPolicy)
+	}
+
+	deleter, ok := p.store.(Deleter)
+	if !ok {
+		return report, ErrStoreUnsupported
+	}
+
+	// The visit history is deleted along with the execution when possible.
+	historyDeleter, _ := p.history.(Deleter)
+	del := func(id ExecutionID) error {
+		if historyDeleter != nil {
+			if err := historyDeleter.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		if err := deleter.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		report.Deleted++
+		return nil
+	}
+
+	now := p.now()
+	policy := p.retention
+
+	expire := func(id ExecutionID, state ExecutionState[T]) error {
+		state.CheckpointState.Status = StatusExpired
+		state.CheckpointState.UpdatedAt = now
+		err := p.update(ctx, id, state)
+		if errors.Is(err, ErrRevisionConflict) {
+			// Resumed since it was loaded.
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		report.Expired++
+		if policy.OnExpire != nil {
+			return policy.OnExpire(ctx, id, state)
+		}
+
+		return nil
+	}
+
+	err := p.forEachExecution(ctx, func(id ExecutionID, state ExecutionState[T]) error {
+		cs := state.CheckpointState
+		if cs.UpdatedAt.IsZero() {
+			return nil
+		}
+
+		age := now.Sub(cs.UpdatedAt)
+
+		switch cs.Status {
+		case StatusCompleted:
+			if policy.KeepCompleted > 0 && age > policy.KeepCompleted {
+				return del(id)
+			}
+		case StatusExpired:
+			if policy.KeepExpired > 0 && age > policy.KeepExpired {
+				return del(id)
+			}
+		case StatusInterrupted:
+			if policy.ExpireInterrupted > 0 && age > policy.ExpireInterrupted {
+				return expire(id, state)
+			}
+		case StatusRunning:
+			if policy.ExpireRunning > 0 && age > policy.ExpireRunning {
+				return expire(id, state)
+			}
+		case StatusWaiting:
+			if policy.ExpireWaiting > 0 && age > policy.ExpireWaiting {
+				return expire(id, state)
+			}
+		case StatusSleeping:
+			if policy.ExpireSleeping > 0 && now.Sub(cs.Timer.WakeAt) > policy.ExpireSleeping {
+				return expire(id, state)
+			}
+		}
+
+		return nil
+	})
+
+	return report, err
+}
+
+// Janitor enforces the retention policies of a set of pipes, either on demand
+// using [Janitor.Sweep] or periodically using [Janitor.Run].
+type Janitor[T any] struct {
+	pipes []*Pipe[T]
+}
+
+// NewJanitor creates a new [Janitor] for the passed pipes.
+func NewJanitor[T any](pipes ...*Pipe[T]) *Janitor[T] {
+	return &Janitor[T]{
+		pipes: pipes,
+	}
+}
+
+// Sweep runs [Pipe.Sweep] for all the pipes of the janitor. All the pipes are swept
+// even when one of them fails, and the errors are joined.
+func (j *Janitor[T]) Sweep(ctx context.Context) (SweepReport, error) {
+	report := SweepReport{}
+	errs := []error{}
+
+	for _, p := range j.pipes {
+		pr, err := p.Sweep(ctx)
+		report.Add(pr)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// Run sweeps all the pipes every interval until the context is cancelled. Sweep errors
+// are passed to the onError callback if it's not nil. [ErrInvalidInterval] is returned
+// for intervals which aren't positive.
+func (j *Janitor[T]) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	return runEvery(ctx, interval, func(ctx context.Context) error {
+		_, err := j.Sweep(ctx)
+		return err
+	}, onError)
+}
+
+// runEvery calls fn every interval until the context is cancelled. The errors of fn are passed
+// to the onError callback if it's not nil. [ErrInvalidInterval] is returned for intervals which
+// aren't positive.
+func runEvery(ctx context.Context, interval time.Duration, fn func(context.Context) error, onError func(error)) error {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := fn(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPipeSweep(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[State]()
+	old := time.Now().Add(-48 * time.Hour)
+	states := map[string]CheckpointState{
+		"completed_old":   {CheckpointID: "a", Status: StatusCompleted, UpdatedAt: old},
+		"completed_new":   {CheckpointID: "a", Status: StatusCompleted, UpdatedAt: time.Now()},
+		"interrupted_old": {CheckpointID: "a", Status: StatusInterrupted, UpdatedAt: old},
+		"interrupted_new": {CheckpointID: "a", Status: StatusInterrupted, UpdatedAt: time.Now()},
+		"running_old":     {CheckpointID: "a", Status: StatusRunning, UpdatedAt: old},
+		"running_new":     {CheckpointID: "a", Status: StatusRunning, UpdatedAt: time.Now()},
+		"waiting_old":     {CheckpointID: "a", Status: StatusWaiting, UpdatedAt: old},
+		"waiting_new":     {CheckpointID: "a", Status: StatusWaiting, UpdatedAt: time.Now()},
+		"sleeping_due":    {CheckpointID: "a", Status: StatusSleeping, UpdatedAt: old, Timer: TimerWait{NodeID: "a", WakeAt: old}},
+		"sleeping_long":   {CheckpointID: "a", Status: StatusSleeping, UpdatedAt: old, Timer: TimerWait{NodeID: "a", WakeAt: time.Now()}},
+	}
+	for id, cs := range states {
+		_ = store.Set(t.Context(), ExecutionID{ID: id, FlowName: "sweep"}, ExecutionState[State]{CheckpointState: cs})
+	}
+
+	expired := []string{}
+	pipe := NewPipe("sweep", graph, store).WithRetention(RetentionPolicy[State]{
+		KeepCompleted:     24 * time.Hour,
+		ExpireInterrupted: 24 * time.Hour,
+		ExpireRunning:     24 * time.Hour,
+		ExpireWaiting:     24 * time.Hour,
+		ExpireSleeping:    24 * time.Hour,
+		OnExpire: func(ctx context.Context, id ExecutionID, state ExecutionState[State]) error {
+			expired = append(expired, id.ID)
+			return nil
+		},
+	})
+
+	report, err := NewJanitor(pipe).Sweep(t.Context())
+	if err != nil {
+		t.Fatalf("error while sweeping: %s", err)
+	}
+
+	if report.Deleted != 1 || report.Expired != 4 {
+		t.Errorf("unexpected sweep report: %+v", report)
+	}
+
+	slices.Sort(expired)
+	if want := []string{"interrupted_old", "running_old", "sleeping_due", "waiting_old"}; !slices.Equal(expired, want) {
+		t.Errorf("expected expiry callbacks for %v, got %v", want, expired)
+	}
+
+	ids, _ := store.List(t.Context(), "sweep")
+	if len(ids) != 9 {
+		t.Errorf("expected 9 executions left, got %d", len(ids))
+	}
+
+	_, err = pipe.Continue(t.Context(), "interrupted_old", ResumeConfig{})
+	if !errors.Is(err, ErrExecutionExpired) {
+		t.Errorf("expected ErrExecutionExpired, got %v", err)
+	}
+}
+
+// failingDeleteStore is an in-memory store whose deletes always fail.
+type failingDeleteStore[T any] struct {
+	*InMemoryStore[T]
+}
+
+func (s failingDeleteStore[T]) Delete(ctx context.Context, id ExecutionID) error {
+	return errors.New("delete failed")
+}
+
+func TestPipeSweepFailedDelete(t *testing.T) {
+	graph, _ := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		SetStartNode("a").
+		Build()
+
+	store := failingDeleteStore[State]{NewInMemoryStore[State]()}
+	_ = store.Set(t.Context(), ExecutionID{ID: "completed_old", FlowName: "sweep"}, ExecutionState[State]{
+		CheckpointState: CheckpointState{CheckpointID: "a", Status: StatusCompleted, UpdatedAt: time.Now().Add(-48 * time.Hour)},
+	})
+
+	report, err := NewPipe("sweep", graph, store).
+		WithRetention(RetentionPolicy[State]{KeepCompleted: 24 * time.Hour}).
+		Sweep(t.Context())
+	if err == nil {
+		t.Errorf("expected the delete error")
+	}
+
+	if report.Deleted != 0 {
+		t.Errorf("expected no deletions to be counted, got %d", report.Deleted)
+	}
+}
+
+func TestJanitorRunInterval(t *testing.T) {
+	if err := NewJanitor[State]().Run(t.Context(), 0, nil); !errors.Is(err, ErrInvalidInterval) {
+		t.Errorf("expected ErrInvalidInterval, got %v", err)
+	}
+}
+
+func TestPipeSweepConcurrentResume(t *testing.T) {
+	graph, _ := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		SetStartNode("a").
+		Build()
+
+	store := &racingStore[State]{InMemoryStore: NewInMemoryStore[State]()}
+	id := ExecutionID{ID: "thread-1", FlowName: "sweep"}
+	_ = store.Set(t.Context(), id, ExecutionState[State]{
+		CheckpointState: CheckpointState{CheckpointID: "a", Status: StatusInterrupted, UpdatedAt: time.Now().Add(-48 * time.Hour)},
+	})
+
+	// The execution is resumed between loading and expiring it.
+	store.race = func() {
+		_ = store.Set(t.Context(), id, ExecutionState[State]{
+			CheckpointState: CheckpointState{CheckpointID: "a", Status: StatusCompleted, UpdatedAt: time.Now()},
+		})
+	}
+
+	report, err := NewPipe("sweep", graph, store).
+		WithRetention(RetentionPolicy[State]{ExpireInterrupted: 24 * time.Hour}).
+		Sweep(t.Context())
+	if err != nil {
+		t.Fatalf("error while sweeping: %s", err)
+	}
+
+	es, _ := store.Get(t.Context(), id)
+	if report.Expired != 0 || es.CheckpointState.Status != StatusCompleted {
+		t.Errorf("expected the resumed execution to be kept, got %+v and %s", report, es.CheckpointState.Status)
+	}
+}
+
+func TestPipeSweepExpireRunningPersistence(t *testing.T) {
+	graph, _ := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		SetStartNode("a").
+		Build()
+
+	_, err := NewPipe("sweep", graph, NewInMemoryStore[State]()).
+		WithPersistence(PersistencePolicy{Mode: PersistOnInterrupt}).
+		WithRetention(RetentionPolicy[State]{ExpireRunning: time.Hour}).
+		Sweep(t.Context())
+	if !errors.Is(err, ErrInvalidRetentionPolicy) {
+		t.Errorf("expected ErrInvalidRetentionPolicy, got %v", err)
+	}
+}
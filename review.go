@@ -0,0 +1,209 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ReviewAction is the action chosen by the reviewer of a [Review] node.
+type ReviewAction string
+
+const (
+	// ReviewApprove accepts the reviewed state as is.
+	ReviewApprove ReviewAction = "approve"
+	// ReviewEdit applies the patch submitted by the reviewer to the state.
+	ReviewEdit ReviewAction = "edit"
+	// ReviewReject rejects the reviewed state with the reason submitted by the reviewer.
+	ReviewReject ReviewAction = "reject"
+)
+
+const (
+	// ReviewReason is the reason of the interrupts raised by [Review] nodes.
+	ReviewReason = "review"
+
+	// ReviewActionKey is the requirement key of the [ReviewAction].
+	ReviewActionKey = "action"
+	// ReviewPatchKey is the requirement key of the JSON merge patch of an edit.
+	ReviewPatchKey = "patch"
+	// ReviewRejectKey is the requirement key of the reason of a rejection.
+	ReviewRejectKey = "reason"
+)
+
+// Review is a [Node] asking a human to approve, edit or reject selected fields of the state.
+// The fields are rendered as the interrupt [HITLInterrupt.Data] and the review is resolved
+// through [Pipe.Continue] with the values:
+//
+//   - action: one of approve, edit or reject.
+//   - patch: the JSON merge patch of the reviewed fields, required for edit.
+//   - reason: the reason of the rejection.
+//
+// The node routes the execution with the chosen action, use [GraphBuilder.AddRoutes] to
+// set the successor of each action:
+//
+//	gb.AddNode("review", flodk.NewReview[Booking]("Please check the booking", "name", "date")).
+//		AddRoutes("review", map[string]string{
+//			string(flodk.ReviewApprove): "book",
+//			string(flodk.ReviewEdit):    "book",
+//			string(flodk.ReviewReject):  "cancel",
+//		})
+type Review[T any] struct {
+	message  string
+	fields   []string
+	validate func(T) error
+	onReject func(state T, reason string) T
+}
+
+// NewReview returns a [Review] node of the passed JSON fields of the state. All the fields
+// are reviewed when none is passed.
+func NewReview[T any](message string, fields ...string) *Review[T] {
+	return &Review[T]{
+		message: message,
+		fields:  fields,
+	}
+}
+
+// WithValidation sets the validation of the edited state. Validation errors are reported
+// to the reviewer as an invalid patch.
+func (r *Review[T]) WithValidation(fn func(T) error) *Review[T] {
+	r.validate = fn
+
+	return r
+}
+
+// OnReject sets the function recording the rejection reason in the state.
+func (r *Review[T]) OnReject(fn func(state T, reason string) T) *Review[T] {
+	r.onReject = fn
+
+	return r
+}
+
+// Execute implements the [Node] interface for [Review].
+func (r *Review[T]) Execute(ctx context.Context, state T) (T, error) {
+	view, err := r.view(state)
+	if err != nil {
+		return state, err
+	}
+
+	data, err := json.Marshal(view)
+	if err != nil {
+		return state, err
+	}
+
+	edited := state
+	values, err := RequestInterrupt(ctx, HITLInterrupt{
+		Reason:       ReviewReason,
+		Message:      r.message,
+		Requirements: r.requirements(),
+		Data:         data,
+	}, func(values map[string]string) error {
+		switch ReviewAction(values[ReviewActionKey]) {
+		case ReviewEdit:
+			edited, err = r.edit(state, view, values[ReviewPatchKey])
+			return err
+		case ReviewReject:
+			if values[ReviewRejectKey] == "" {
+				return ErrRequirmentKeyNotFound(ReviewRejectKey)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return state, err
+	}
+
+	action := ReviewAction(values[ReviewActionKey])
+	switch action {
+	case ReviewEdit:
+		state = edited
+	case ReviewReject:
+		if r.onReject != nil {
+			state = r.onReject(state, values[ReviewRejectKey])
+		}
+	}
+
+	return state, ConitionalInterrupt{Value: string(action)}
+}
+
+// requirements returns the requirements of the review interrupt.
+func (r *Review[T]) requirements() Requirements {
+	return Requirements{
+		ReviewActionKey: {
+			Type:        Enum,
+			Suggestions: []string{string(ReviewApprove), string(ReviewEdit), string(ReviewReject)},
+			Label:       "Action",
+		},
+		ReviewPatchKey: {
+			Type:        JSON,
+			Optional:    true,
+			Label:       "Changes",
+			Description: "JSON merge patch of the reviewed fields",
+			Order:       1,
+		},
+		ReviewRejectKey: {
+			Type:     Custom,
+			Optional: true,
+			Label:    "Reason",
+			Order:    2,
+		},
+	}
+}
+
+// view returns the reviewed fields of the state.
+func (r *Review[T]) view(state T) (map[string]any, error) {
+	doc, err := jsonObject(state)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.fields) == 0 {
+		return doc, nil
+	}
+
+	view := make(map[string]any, len(r.fields))
+	for _, field := range r.fields {
+		view[field] = doc[field]
+	}
+
+	return view, nil
+}
+
+// edit applies the patch of the reviewer to the state, the patch may only change the reviewed fields.
+func (r *Review[T]) edit(state T, view map[string]any, patch string) (T, error) {
+	if patch == "" {
+		return state, ErrRequirmentKeyNotFound(ReviewPatchKey)
+	}
+
+	changes := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(patch), &changes); err != nil {
+		return state, RequirementInvalidReason(ReviewPatchKey, patch, "must be a JSON object")
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(changes)) {
+		if _, ok := view[field]; !ok {
+			return state, RequirementInvalidReason(ReviewPatchKey, patch, fmt.Sprintf("field '%s' is not reviewed", field))
+		}
+	}
+
+	edited, err := ApplyMergePatch(state, []byte(patch))
+	if err != nil {
+		return state, RequirementInvalidReason(ReviewPatchKey, patch, err.Error())
+	}
+
+	if r.validate != nil {
+		if err := r.validate(edited); err != nil {
+			var invalid ErrRequirementInvalidValue
+			if errors.As(err, &invalid) {
+				return state, err
+			}
+
+			return state, RequirementInvalidReason(ReviewPatchKey, patch, err.Error())
+		}
+	}
+
+	return edited, nil
+}
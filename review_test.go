@@ -0,0 +1,163 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type booking struct {
+	Name     string            `json:"name"`
+	Date     string            `json:"date"`
+	Extras   map[string]string `json:"extras,omitempty"`
+	Status   string            `json:"status"`
+	Rejected string            `json:"-"`
+}
+
+func reviewPipe(t *testing.T) *Pipe[booking] {
+	t.Helper()
+
+	status := func(s string) Node[booking] {
+		return FunctionNode[booking](func(ctx context.Context, state booking) (booking, error) {
+			state.Status = s
+			return state, nil
+		})
+	}
+
+	graph, err := NewGraphBuilder[booking]().
+		AddNode("review", NewReview[booking]("Please check the booking", "name", "date", "extras").
+			WithValidation(func(b booking) error {
+				if b.Name == "" {
+					return errors.New("name is required")
+				}
+				return nil
+			}).
+			OnReject(func(b booking, reason string) booking {
+				b.Rejected = reason
+				return b
+			})).
+		AddNode("book", status("booked")).
+		AddNode("cancel", status("cancelled")).
+		AddRoutes("review", map[string]string{
+			string(ReviewApprove): "book",
+			string(ReviewEdit):    "book",
+			string(ReviewReject):  "cancel",
+		}).
+		SetStartNode("review").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	return NewPipe("review", graph, NewInMemoryStore[booking]())
+}
+
+func TestReview(t *testing.T) {
+	initial := booking{Name: "John", Date: "2026-05-01", Extras: map[string]string{"meal": "veg"}}
+
+	tests := map[string]struct {
+		values map[string]string
+		want   booking
+	}{
+		"approve": {
+			values: map[string]string{"action": "approve"},
+			want:   booking{Name: "John", Date: "2026-05-01", Extras: map[string]string{"meal": "veg"}, Status: "booked"},
+		},
+		"edit": {
+			values: map[string]string{"action": "edit", "patch": `{"date":"2026-05-02","extras":{"meal":null,"bag":"1"}}`},
+			want:   booking{Name: "John", Date: "2026-05-02", Extras: map[string]string{"bag": "1"}, Status: "booked"},
+		},
+		"reject": {
+			values: map[string]string{"action": "reject", "reason": "wrong passenger"},
+			want:   booking{Name: "John", Date: "2026-05-01", Extras: map[string]string{"meal": "veg"}, Status: "cancelled", Rejected: "wrong passenger"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pipe := reviewPipe(t)
+
+			var interrupt HITLInterrupt
+			if _, err := pipe.Invoke(t.Context(), "thread-1", initial); !errors.As(err, &interrupt) {
+				t.Fatalf("expected review interrupt, got %v", err)
+			}
+
+			view := map[string]any{}
+			if err := json.Unmarshal(interrupt.Data, &view); err != nil || view["name"] != "John" || len(view) != 3 {
+				t.Errorf("unexpected review data: %s", interrupt.Data)
+			}
+
+			state, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: tc.values})
+			if err != nil {
+				t.Fatalf("error while continuing the flow: %s", err)
+			}
+
+			got, _ := json.Marshal(state)
+			want, _ := json.Marshal(tc.want)
+			if string(got) != string(want) || state.Rejected != tc.want.Rejected {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+		})
+	}
+
+	if initial.Extras["meal"] != "veg" {
+		t.Error("expected the edit to leave the initial state unchanged")
+	}
+}
+
+func TestReviewInvalidEdit(t *testing.T) {
+	pipe := reviewPipe(t)
+	if _, err := pipe.Invoke(t.Context(), "thread-1", booking{Name: "John"}); err == nil {
+		t.Fatal("expected review interrupt, got nil")
+	}
+
+	for _, patch := range []string{`{"status":"booked"}`, `{"name":""}`} {
+		_, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"action": "edit", "patch": patch}})
+
+		var interrupt HITLInterrupt
+		if !errors.As(err, &interrupt) || interrupt.ValidationError == nil || interrupt.ValidationError.Fields[0].Key != ReviewPatchKey {
+			t.Errorf("expected patch validation error for %s, got %v", patch, err)
+		}
+	}
+}
+
+func TestRouteNotFound(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			return state, ConitionalInterrupt{Value: "unknown"}
+		})).
+		AddNode("b", AdderNode(1)).
+		AddRoutes("a", map[string]string{"known": "b"}).
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	_, err = NewFlow("routes", graph).Execute(t.Context(), State{})
+
+	var notFound RouteNotFoundError
+	if !errors.As(err, &notFound) || notFound.Value != "unknown" {
+		t.Errorf("expected RouteNotFoundError, got %v", err)
+	}
+}
+
+func TestRouteMissing(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		AddNode("b", AdderNode(1)).
+		AddRoutes("a", map[string]string{"known": "b"}).
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	_, err = NewFlow("routes", graph).Execute(t.Context(), State{})
+
+	var notFound RouteNotFoundError
+	if !errors.As(err, &notFound) || notFound.NodeID != "a" || notFound.Value != "" {
+		t.Errorf("expected RouteNotFoundError for node a, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package flodk
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// SchemaDialect is the JSON Schema dialect of the exported schemas.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document describing the values of interrupt requirements.
+// Besides the standard keywords, UI hints are exported as `x-` annotations which form
+// libraries can map to their own options. Go regular expressions and time layouts don't
+// translate to JSON Schema, so they're exported as `x-go-` annotations.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Examples    []string           `json:"examples,omitempty"`
+	Default     any                `json:"default,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	UniqueItems bool               `json:"uniqueItems,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Format      string             `json:"format,omitempty"`
+
+	// AdditionalProperties is false for the requirements object.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+
+	// Placeholder is the example text shown in empty inputs.
+	Placeholder string `json:"x-placeholder,omitempty"`
+	// Order is the order the properties should be rendered in.
+	Order []string `json:"x-order,omitempty"`
+	// GoLayout is the Go time layout of date/time values.
+	GoLayout string `json:"x-go-layout,omitempty"`
+	// GoPattern is the Go regular expression string values must fully match.
+	GoPattern string `json:"x-go-pattern,omitempty"`
+	// Errors are the validation error messages of the property from the previous submission.
+	Errors []string `json:"x-errors,omitempty"`
+
+	// Reason is the reason of the interrupt.
+	Reason string `json:"x-reason,omitempty"`
+	// InterruptID identifies the interrupt the values are submitted for.
+	InterruptID *InterruptID `json:"x-interrupt-id,omitempty"`
+	// ValidationError is the validation error of the previous submission.
+	ValidationError *ValidationError `json:"x-validation-error,omitempty"`
+	// Data is the JSON document shown along with the interrupt.
+	Data json.RawMessage `json:"x-data,omitempty"`
+}
+
+// OrderedKeys returns the requirement keys ordered by [Requirement.Order] and then by key.
+func (r Requirements) OrderedKeys() []string {
+	return slices.SortedFunc(maps.Keys(r), func(a, b string) int {
+		return cmp.Or(cmp.Compare(r[a].Order, r[b].Order), cmp.Compare(a, b))
+	})
+}
+
+// JSONSchema exports the requirements as a JSON Schema of an object with a property
+// per requirement. The values must be submitted as strings ([ResumeConfig.InterruptValues]),
+// use [Requirements.ValuesFromJSON] to convert the JSON object produced by a form.
+func (r Requirements) JSONSchema() *Schema {
+	noAdditional := false
+	schema := &Schema{
+		Schema:               SchemaDialect,
+		Type:                 "object",
+		Properties:           make(map[string]*Schema, len(r)),
+		Required:             []string{},
+		Order:                r.OrderedKeys(),
+		AdditionalProperties: &noAdditional,
+	}
+
+	for _, key := range schema.Order {
+		req := r[key]
+		schema.Properties[key] = req.JSONSchema(key)
+
+		if !req.Optional && req.Default == "" {
+			schema.Required = append(schema.Required, key)
+		}
+	}
+
+	return schema
+}
+
+// JSONSchema exports the requirement as the JSON Schema of its value.
+func (r Requirement) JSONSchema(key string) *Schema {
+	schema := &Schema{
+		Title:       cmp.Or(r.Label, key),
+		Description: r.Description,
+		Placeholder: r.Placeholder,
+	}
+
+	switch r.Type {
+	case Enum:
+		schema.Type = "string"
+		schema.Enum = r.Suggestions
+	case CustomWithSuggestions:
+		schema.Type = "string"
+		schema.Examples = r.Suggestions
+	case Integer:
+		schema.Type = "integer"
+		schema.Minimum, schema.Maximum = r.Min, r.Max
+	case Decimal:
+		schema.Type = "number"
+		schema.Minimum, schema.Maximum = r.Min, r.Max
+	case Boolean:
+		schema.Type = "boolean"
+	case DateTime:
+		schema.Type = "string"
+		schema.GoLayout = cmp.Or(r.Format, time.RFC3339)
+		switch schema.GoLayout {
+		case time.RFC3339, time.RFC3339Nano:
+			schema.Format = "date-time"
+		case time.DateOnly:
+			schema.Format = "date"
+		case time.TimeOnly:
+			schema.Format = "time"
+		}
+	case Pattern:
+		schema.Type = "string"
+		schema.GoPattern = "^(?:" + r.Pattern + ")$"
+	case MultiSelect:
+		schema.Type = "array"
+		schema.UniqueItems = true
+		schema.Items = &Schema{Type: "string", Enum: r.Suggestions}
+		if r.Min != nil {
+			n := int(*r.Min)
+			schema.MinItems = &n
+		}
+		if r.Max != nil {
+			n := int(*r.Max)
+			schema.MaxItems = &n
+		}
+	case JSON:
+		// Any JSON value is accepted.
+	default:
+		schema.Type = "string"
+	}
+
+	if r.Default != "" {
+		schema.Default = r.typedDefault(key)
+	}
+
+	return schema
+}
+
+// typedDefault returns the default value as the JSON type of the requirement.
+func (r Requirement) typedDefault(key string) any {
+	value, err := r.Coerce(key, r.Default)
+	if err != nil {
+		return r.Default
+	}
+
+	switch r.Type {
+	case Integer, Decimal, Boolean, MultiSelect, JSON:
+		return json.RawMessage(value)
+	}
+
+	return value
+}
+
+// JSONSchema exports the interrupt as a JSON Schema of the values required to resolve it.
+// The message is exported as the title and the reason, interrupt ID and the validation
+// errors of the previous submission as annotations.
+func (it HITLInterrupt) JSONSchema() *Schema {
+	schema := it.Requirements.JSONSchema()
+	schema.Title = it.Message
+	schema.Reason = it.Reason
+	schema.InterruptID = &it.InterruptID
+	schema.ValidationError = it.ValidationError
+	schema.Data = it.Data
+
+	if it.ValidationError != nil {
+		for _, fe := range it.ValidationError.Fields {
+			if prop, ok := schema.Properties[fe.Key]; ok {
+				prop.Errors = append(prop.Errors, fe.Message)
+			}
+		}
+	}
+
+	return schema
+}
+
+// ValuesFromJSON converts a JSON object, as produced by a form rendered from a [Schema], into
+// the string values accepted by [ResumeConfig.InterruptValues]. Strings are kept as is, nulls
+// are dropped and any other value is kept as its JSON text. Use [Requirements.ValuesFromJSON]
+// when the requirements include [JSON] values.
+func ValuesFromJSON(data []byte) (map[string]string, error) {
+	return Requirements(nil).ValuesFromJSON(data)
+}
+
+// ValuesFromJSON converts a JSON object, as produced by a form rendered from the [Schema] of
+// the requirements, into the string values accepted by [ResumeConfig.InterruptValues], see
+// [ValuesFromJSON]. Values of [JSON] requirements are always kept as their JSON text, so that
+// JSON strings aren't unquoted.
+func (r Requirements) ValuesFromJSON(data []byte) (map[string]string, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(fields))
+	for key, raw := range fields {
+		var s string
+		switch {
+		case string(raw) == "null":
+			continue
+		case r[key].Type == JSON && json.Valid(raw):
+			values[key] = string(raw)
+		case json.Unmarshal(raw, &s) == nil:
+			values[key] = s
+		case json.Valid(raw):
+			values[key] = string(raw)
+		default:
+			return nil, errors.New("invalid value for " + strconv.Quote(key))
+		}
+	}
+
+	return values, nil
+}
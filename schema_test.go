@@ -0,0 +1,122 @@
+package flodk
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRequirementsJSONSchema(t *testing.T) {
+	reqs := Requirements{
+		"passengers": {Type: Integer, Min: Bound(1), Max: Bound(9), Default: "1", Label: "Passengers", Order: 2},
+		"date":       {Type: DateTime, Format: time.DateOnly, Label: "Travel date", Order: 1},
+		"extras":     {Type: MultiSelect, Suggestions: []string{"meal", "bag"}, Max: Bound(2), Order: 3},
+		"notes":      {Type: Custom, Optional: true, Placeholder: "Window seat please", Order: 3},
+		"code":       {Type: Pattern, Pattern: `[A-Z]{3}`, Order: 4},
+	}
+
+	schema := reqs.JSONSchema()
+
+	if want := []string{"date", "passengers", "extras", "notes", "code"}; !slices.Equal(schema.Order, want) {
+		t.Errorf("expected order %v, got %v", want, schema.Order)
+	}
+
+	if want := []string{"date", "extras", "code"}; !slices.Equal(schema.Required, want) {
+		t.Errorf("expected required %v, got %v", want, schema.Required)
+	}
+
+	date := schema.Properties["date"]
+	if date.Type != "string" || date.Format != "date" || date.GoLayout != time.DateOnly || date.Title != "Travel date" {
+		t.Errorf("unexpected date schema: %+v", date)
+	}
+
+	passengers := schema.Properties["passengers"]
+	if passengers.Type != "integer" || *passengers.Maximum != 9 {
+		t.Errorf("unexpected passengers schema: %+v", passengers)
+	}
+
+	extras := schema.Properties["extras"]
+	if extras.Type != "array" || *extras.MaxItems != 2 || !slices.Equal(extras.Items.Enum, []string{"meal", "bag"}) {
+		t.Errorf("unexpected extras schema: %+v", extras)
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	props := doc["properties"].(map[string]any)
+	if got := props["passengers"].(map[string]any)["default"]; got != float64(1) {
+		t.Errorf("expected numeric default, got %#v", got)
+	}
+
+	if got := props["notes"].(map[string]any)["x-placeholder"]; got != "Window seat please" {
+		t.Errorf("expected placeholder, got %#v", got)
+	}
+
+	code := props["code"].(map[string]any)
+	if _, ok := code["pattern"]; ok || code["x-go-pattern"] != "^(?:[A-Z]{3})$" {
+		t.Errorf("expected the Go pattern to be exported as x-go-pattern, got %#v", code)
+	}
+}
+
+func TestInterruptJSONSchema(t *testing.T) {
+	it := HITLInterrupt{
+		Reason:       "confirm",
+		Message:      "How many passengers?",
+		InterruptID:  InterruptID{NodeID: "ask", ID: "i-1"},
+		Requirements: Requirements{"passengers": {Type: Integer}},
+	}
+
+	_, err := it.Requirements.Resolve(map[string]string{"passengers": "many"})
+	it.ValidationError = NewValidationError(err)
+
+	schema := it.JSONSchema()
+	if schema.Title != it.Message || schema.Reason != it.Reason || *schema.InterruptID != it.InterruptID {
+		t.Errorf("unexpected envelope: %+v", schema)
+	}
+
+	if errs := schema.Properties["passengers"].Errors; len(errs) != 1 {
+		t.Errorf("expected one field error, got %v", errs)
+	}
+}
+
+func TestValuesFromJSON(t *testing.T) {
+	values, err := ValuesFromJSON([]byte(`{"date":"2026-05-01","passengers":2,"window":true,"extras":["meal"],"notes":null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"date": "2026-05-01", "passengers": "2", "window": "true", "extras": `["meal"]`}
+	if !maps.Equal(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+}
+
+func TestRequirementsValuesFromJSON(t *testing.T) {
+	reqs := Requirements{
+		"name":  {Type: Custom},
+		"extra": {Type: JSON},
+	}
+
+	values, err := reqs.ValuesFromJSON([]byte(`{"name":"Jane Doe","extra":"window seat"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"name": "Jane Doe", "extra": `"window seat"`}
+	if !maps.Equal(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+
+	if _, err := reqs.Resolve(values); err != nil {
+		t.Errorf("expected the values to resolve, got %s", err)
+	}
+}
@@ -0,0 +1,174 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoPendingSignal is returned when signalling an execution which isn't waiting on a signal.
+var ErrNoPendingSignal = errors.New("execution isn't waiting on a signal")
+
+// UnexpectedSignalError is returned when signalling an execution waiting on another signal.
+type UnexpectedSignalError struct {
+	Name    string
+	Waiting string
+}
+
+// Error implements the error interface for the unexpected signal error.
+func (us UnexpectedSignalError) Error() string {
+	return fmt.Sprintf("unexpected signal '%s': execution is waiting on signal '%s'", us.Name, us.Waiting)
+}
+
+// SignalWait is returned by nodes to suspend the execution until the named signal is
+// delivered using [Pipe.Signal], see [WaitForSignal].
+type SignalWait struct {
+	NodeID string `json:"node_id"`
+	Name   string `json:"name"`
+}
+
+// Error implements the error interface for the signal wait.
+func (sw SignalWait) Error() string {
+	return fmt.Sprintf("flow waiting on signal: %s", sw.Name)
+}
+
+// suspend implements the suspension interface for [SignalWait].
+func (sw SignalWait) suspend(cs *CheckpointState) {
+	cs.Signal = sw
+	cs.Status = StatusWaiting
+}
+
+// ReceivedSignal contains the signal waited on and the payload it was delivered with.
+type ReceivedSignal struct {
+	SignalWait
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// Step is the step (number of visits) of the execution the signal was received in.
+	Step int `json:"step,omitempty"`
+	// ReceivedAt is the time the signal was delivered.
+	ReceivedAt time.Time `json:"received_at,omitzero"`
+}
+
+// signalKey is the context key of a received signal of a graph node.
+type signalKey struct {
+	nodeID string
+	name   string
+}
+
+// loadSignal loads the context with the received signal, keyed by its node ID and name.
+func loadSignal(ctx context.Context, rs ReceivedSignal) context.Context {
+	return context.WithValue(ctx, signalKey{nodeID: rs.NodeID, name: rs.Name}, rs)
+}
+
+// getLoadedSignal returns the received signal of a node from the context.
+func getLoadedSignal(ctx context.Context, nodeID string, name string) (ReceivedSignal, bool) {
+	rs, ok := ctx.Value(signalKey{nodeID: nodeID, name: name}).(ReceivedSignal)
+	return rs, ok
+}
+
+// WaitForSignal returns the payload of the named signal once it's delivered using [Pipe.Signal].
+// Until then a [SignalWait] is returned as the error, which the node must return to suspend the
+// execution. Like interrupts, a node can wait on several signals in sequence.
+//
+//	payment, err := flodk.WaitForSignal[PaymentEvent](ctx, "payment")
+//	if err != nil {
+//		return state, err
+//	}
+func WaitForSignal[P any](ctx context.Context, name string) (P, error) {
+	var payload P
+
+	nodeID, ok := GetNodeID(ctx)
+	if !ok {
+		return payload, ErrNodeIDNotFound
+	}
+
+	rs, ok := getLoadedSignal(ctx, nodeID, name)
+	if !ok {
+		return payload, SignalWait{
+			NodeID: nodeID,
+			Name:   name,
+		}
+	}
+
+	if len(rs.Payload) == 0 {
+		return payload, nil
+	}
+
+	if err := json.Unmarshal(rs.Payload, &payload); err != nil {
+		return payload, fmt.Errorf("invalid payload of signal '%s': %w", name, err)
+	}
+
+	return payload, nil
+}
+
+// SignalNode returns a [Node] waiting on the named signal, which applies the payload of the
+// signal to the state once it's delivered.
+func SignalNode[T, P any](name string, apply func(state T, payload P) T) Node[T] {
+	return FunctionNode[T](func(ctx context.Context, state T) (T, error) {
+		payload, err := WaitForSignal[P](ctx, name)
+		if err != nil {
+			return state, err
+		}
+
+		return apply(state, payload), nil
+	})
+}
+
+// Signal delivers the named signal with the payload, encoded as JSON, to the execution and
+// resumes it. [ErrNoPendingSignal] is returned for executions which aren't waiting on a signal
+// and [UnexpectedSignalError] for executions waiting on another signal. When the store implements
+// [CompareAndSwapper], concurrent deliveries of the signal resume the execution only once, the
+// others get [ErrNoPendingSignal].
+func (p *Pipe[T]) Signal(ctx context.Context, id string, name string, payload any) (T, error) {
+	execState, err := p.load(ctx, ExecutionID{
+		ID:       id,
+		FlowName: p.name,
+	})
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	cs := execState.CheckpointState
+	switch {
+	case cs.Status == StatusExpired:
+		return execState.ApplicationState, ErrExecutionExpired
+	case cs.Status == StatusFailed:
+		return execState.ApplicationState, ErrExecutionFailed
+	case cs.Status != StatusWaiting || cs.Signal.NodeID == "":
+		return execState.ApplicationState, ErrNoPendingSignal
+	case cs.Signal.Name != name:
+		return execState.ApplicationState, UnexpectedSignalError{
+			Name:    name,
+			Waiting: cs.Signal.Name,
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	graph, err := p.graphFor(cs)
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	claimed := execState
+	claimed.CheckpointState.Status = StatusRunning
+	release, err := p.claim(ctx, id, execState, claimed)
+	if errors.Is(err, ErrRevisionConflict) {
+		return execState.ApplicationState, ErrNoPendingSignal
+	}
+
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	state, err := p.invoke(loadSignal(ctx, ReceivedSignal{
+		SignalWait: cs.Signal,
+		Payload:    data,
+		ReceivedAt: p.now(),
+	}), id, graph, cs, execState.ApplicationState)
+	return state, release(err)
+}
@@ -0,0 +1,183 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type paymentEvent struct {
+	Amount int `json:"amount"`
+}
+
+func TestPipeSignal(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("start", AdderNode(1)).
+		AddNode("pay", SignalNode("payment", func(state State, payment paymentEvent) State {
+			state.sum += payment.Amount
+			return state
+		})).
+		AddNode("end", AdderNode(10)).
+		AddEdge("start", "pay").
+		AddEdge("pay", "end").
+		SetStartNode("start").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("signals", graph, store)
+
+	var wait SignalWait
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); !errors.As(err, &wait) || wait.Name != "payment" {
+		t.Fatalf("expected to wait on the payment signal, got %v", err)
+	}
+
+	if _, err := pipe.Continue(t.Context(), "thread-1", ResumeConfig{}); !errors.Is(err, ErrNoPendingInterrupt) {
+		t.Errorf("expected ErrNoPendingInterrupt, got %v", err)
+	}
+
+	var unexpected UnexpectedSignalError
+	if _, err := pipe.Signal(t.Context(), "thread-1", "upload", nil); !errors.As(err, &unexpected) {
+		t.Errorf("expected UnexpectedSignalError, got %v", err)
+	}
+
+	state, err := pipe.Signal(t.Context(), "thread-1", "payment", paymentEvent{Amount: 5})
+	if err != nil {
+		t.Fatalf("error while signalling the flow: %s", err)
+	}
+
+	if state.sum != 16 {
+		t.Errorf("expected sum 16, got %d", state.sum)
+	}
+
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "signals"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := es.CheckpointState
+	if cs.Status != StatusCompleted || len(cs.SignalHistory) != 1 || string(cs.SignalHistory[0].Payload) != `{"amount":5}` {
+		t.Errorf("unexpected checkpoint state: %+v", cs)
+	}
+
+	if _, err := pipe.Signal(t.Context(), "thread-1", "payment", nil); !errors.Is(err, ErrNoPendingSignal) {
+		t.Errorf("expected ErrNoPendingSignal, got %v", err)
+	}
+}
+
+func TestPipeSequentialSignals(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("documents", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			sum := 0
+			for _, name := range []string{"upload", "scan"} {
+				n, err := WaitForSignal[int](ctx, name)
+				if err != nil {
+					return state, err
+				}
+
+				sum += n
+			}
+
+			state.sum += sum
+			return state, nil
+		})).
+		SetStartNode("documents").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("documents", graph, NewInMemoryStore[State]())
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected signal wait, got nil")
+	}
+
+	var wait SignalWait
+	if _, err := pipe.Signal(t.Context(), "thread-1", "upload", 2); !errors.As(err, &wait) || wait.Name != "scan" {
+		t.Fatalf("expected to wait on the scan signal, got %v", err)
+	}
+
+	state, err := pipe.Signal(t.Context(), "thread-1", "scan", 3)
+	if err != nil || state.sum != 5 {
+		t.Errorf("expected sum 5, got %d with %v", state.sum, err)
+	}
+}
+
+func TestPipeSignalConcurrently(t *testing.T) {
+	applied := 0
+	graph, err := NewGraphBuilder[State]().
+		AddNode("pay", SignalNode("payment", func(state State, payment paymentEvent) State {
+			applied++
+			state.sum += payment.Amount
+			return state
+		})).
+		SetStartNode("pay").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := &racingStore[State]{InMemoryStore: NewInMemoryStore[State]()}
+	pipe := NewPipe("signals", graph, store)
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the flow to wait on the signal")
+	}
+
+	store.race = func() {
+		if _, err := pipe.Signal(t.Context(), "thread-1", "payment", paymentEvent{Amount: 5}); err != nil {
+			t.Errorf("error while signalling the flow: %s", err)
+		}
+	}
+
+	if _, err := pipe.Signal(t.Context(), "thread-1", "payment", paymentEvent{Amount: 5}); !errors.Is(err, ErrNoPendingSignal) {
+		t.Errorf("expected ErrNoPendingSignal for the concurrent signal, got %v", err)
+	}
+
+	if applied != 1 {
+		t.Errorf("expected the signal to be applied once, got %d", applied)
+	}
+}
+
+func TestPipeSignalNodeError(t *testing.T) {
+	fail := true
+	graph, err := NewGraphBuilder[State]().
+		AddNode("pay", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			payment, err := WaitForSignal[paymentEvent](ctx, "payment")
+			if err != nil {
+				return state, err
+			}
+
+			if fail {
+				return state, errors.New("node failed")
+			}
+
+			state.sum += payment.Amount
+			return state, nil
+		})).
+		SetStartNode("pay").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	pipe := NewPipe("signals", graph, NewInMemoryStore[State]())
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the flow to wait on the signal")
+	}
+
+	if _, err := pipe.Signal(t.Context(), "thread-1", "payment", paymentEvent{Amount: 5}); err == nil {
+		t.Fatal("expected the node error")
+	}
+
+	fail = false
+	state, err := pipe.Signal(t.Context(), "thread-1", "payment", paymentEvent{Amount: 5})
+	if err != nil {
+		t.Fatalf("error while signalling the flow again: %s", err)
+	}
+
+	if state.sum != 5 {
+		t.Errorf("expected sum 5, got %d", state.sum)
+	}
+}
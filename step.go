@@ -0,0 +1,117 @@
+package flodk
+
+import "context"
+
+// StepResult describes the node executed by [Pipe.Step].
+type StepResult[T any] struct {
+	// State is the application state after the step.
+	State T
+	// Transition is the executed node and the edge taken after it. Only From is set when
+	// the node suspended the execution, and no field is set when no node was executed.
+	Transition Transition
+	// Status is the status of the execution after the step.
+	Status ExecutionStatus
+}
+
+// Done returns true when the execution is completed.
+func (sr StepResult[T]) Done() bool {
+	return sr.Status == StatusCompleted
+}
+
+// Prepare creates an execution for the given unique identifier with the passed initial state,
+// checkpointed at the start node, without executing any node. Use [Pipe.Step] to execute it
+// node by node or [Pipe.Recover] to run it.
+func (p *Pipe[T]) Prepare(ctx context.Context, id string, initState T) error {
+	cs := p.newCheckpoint()
+	cs.CheckpointID = p.graph.start
+	cs.GraphVersion = p.graph.version
+
+	return p.persistStateFunc(ctx, id)(cs, initState)
+}
+
+// Step executes exactly one node of a running execution and persists the execution state,
+// regardless of the persistence policy of the pipe. It's meant for walking a graph node by
+// node while inspecting the state between the steps, e.g. from a test or a REPL.
+//
+// Interrupted executions return their pending interrupt as the error, use [Pipe.StepResume]
+// to resolve it. Completed executions return a done result without executing any node.
+func (p *Pipe[T]) Step(ctx context.Context, id string) (StepResult[T], error) {
+	execState, err := p.load(ctx, ExecutionID{
+		ID:       id,
+		FlowName: p.name,
+	})
+	if err != nil {
+		return StepResult[T]{}, err
+	}
+
+	cs := execState.CheckpointState
+	result := StepResult[T]{
+		State:  execState.ApplicationState,
+		Status: cs.Status,
+	}
+
+	switch cs.Status {
+	case StatusRunning:
+	case StatusCompleted:
+		return result, nil
+	case StatusInterrupted:
+		return result, cs.Interrupt
+	case StatusExpired:
+		return result, ErrExecutionExpired
+	case StatusFailed:
+		return result, ErrExecutionFailed
+	default:
+		return result, ErrExecutionNotRunning
+	}
+
+	graph, err := p.graphFor(cs)
+	if err != nil {
+		return result, err
+	}
+
+	return p.step(ctx, id, graph, cs, execState.ApplicationState)
+}
+
+// StepResume resolves the pending interrupt of the execution like [Pipe.Continue], but only
+// executes the interrupted node, see [Pipe.Step].
+func (p *Pipe[T]) StepResume(ctx context.Context, id string, rc ResumeConfig) (StepResult[T], error) {
+	var result StepResult[T]
+
+	state, err := p.continueWith(ctx, id, rc, func(
+		ctx context.Context,
+		id string,
+		graph Graph[T],
+		checkpointState CheckpointState,
+		initState T,
+	) (T, error) {
+		var err error
+		result, err = p.step(ctx, id, graph, checkpointState, initState)
+
+		return result.State, err
+	})
+
+	result.State = state
+
+	return result, err
+}
+
+// step executes one node of the execution and persists the execution state.
+func (p *Pipe[T]) step(
+	ctx context.Context,
+	id string,
+	graph Graph[T],
+	checkpointState CheckpointState,
+	initState T,
+) (StepResult[T], error) {
+	flow := p.newFlow(ctx, id, graph, checkpointState).
+		WithMaxSteps(1).
+		OnNodeExec(p.persistStateFunc(ctx, id))
+
+	state, err := flow.Execute(ctx, initState)
+
+	return StepResult[T]{
+		State:      state,
+		Transition: flow.LastTransition(),
+		Status:     flow.Checkpoint().Status,
+	}, err
+}
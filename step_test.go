@@ -0,0 +1,76 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPipeStep(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("a", AdderNode(1)).
+		AddNode("ask", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			_, err := Interrupt(ctx, "Continue?", "confirm", Requirements{"ok": {Type: Boolean}})
+			return state, err
+		})).
+		AddNode("b", AdderNode(2)).
+		AddNode("end", Noop[State]()).
+		AddEdge("a", "ask").
+		AddEdge("ask", "b").
+		AddConditionalEdge("b", GtNode(3), map[string]string{
+			Continue: "a",
+			End:      "end",
+		}).
+		SetStartNode("a").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("steps", graph, store).WithPersistence(PersistencePolicy{Mode: PersistOnInterrupt})
+	if err := pipe.Prepare(t.Context(), "thread-1", State{}); err != nil {
+		t.Fatalf("error while preparing the execution: %s", err)
+	}
+
+	result, err := pipe.Step(t.Context(), "thread-1")
+	if err != nil || result.Transition != (Transition{From: "a", To: "ask"}) || result.State.sum != 1 {
+		t.Fatalf("unexpected first step: %+v, %v", result, err)
+	}
+
+	// Steps are persisted regardless of the persistence policy.
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "steps"})
+	if err != nil || es.CheckpointState.CheckpointID != "ask" || es.ApplicationState.sum != 1 {
+		t.Fatalf("expected the step to be persisted, got %+v, %v", es, err)
+	}
+
+	var interrupt HITLInterrupt
+	result, err = pipe.Step(t.Context(), "thread-1")
+	if !errors.As(err, &interrupt) || result.Transition.From != "ask" || result.Status != StatusInterrupted {
+		t.Fatalf("expected the ask node to interrupt, got %+v, %v", result, err)
+	}
+
+	if _, err := pipe.Step(t.Context(), "thread-1"); !errors.As(err, &interrupt) {
+		t.Errorf("expected the pending interrupt, got %v", err)
+	}
+
+	result, err = pipe.StepResume(t.Context(), "thread-1", ResumeConfig{InterruptValues: map[string]string{"ok": "yes"}})
+	if err != nil || result.Transition != (Transition{From: "ask", To: "b"}) || result.Status != StatusRunning {
+		t.Fatalf("unexpected resumed step: %+v, %v", result, err)
+	}
+
+	result, err = pipe.Step(t.Context(), "thread-1")
+	if err != nil || result.Transition != (Transition{From: "b", To: "end", Key: End}) || result.State.sum != 3 {
+		t.Fatalf("unexpected conditional step: %+v, %v", result, err)
+	}
+
+	result, err = pipe.Step(t.Context(), "thread-1")
+	if err != nil || result.Transition != (Transition{From: "end"}) || !result.Done() {
+		t.Fatalf("unexpected last step: %+v, %v", result, err)
+	}
+
+	result, err = pipe.Step(t.Context(), "thread-1")
+	if err != nil || result.Transition != (Transition{}) || !result.Done() {
+		t.Errorf("expected a done result without executing a node, got %+v, %v", result, err)
+	}
+}
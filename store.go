@@ -2,14 +2,50 @@ package flodk
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
 )
 
 // Store interface defines all the necessary functions used to store the execution and application state.
+//
+// Get returns [ErrExecutionNotFound] (optionally wrapped) when nothing is persisted for the ID. Set overwrites any
+// persisted state. Executions are isolated by both the ID and the flow name of the [ExecutionID].
+// The storetest package implements a conformance suite for Store implementations.
 type Store[T any] interface {
 	Get(ctx context.Context, id ExecutionID) (ExecutionState[T], error)
 	Set(ctx context.Context, id ExecutionID, state ExecutionState[T]) error
 }
 
+// ErrExecutionNotFound is returned by [Store.Get] when no execution is persisted for the ID.
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ErrStoreUnsupported is returned when an operation needs an optional store capability
+// (like [Lister] or [Deleter]) which the store doesn't implement.
+var ErrStoreUnsupported = errors.New("store doesn't support the operation")
+
+// Lister is an optional capability of a [Store] which lists all the persisted executions of a flow.
+type Lister interface {
+	List(ctx context.Context, flowName string) ([]ExecutionID, error)
+}
+
+// Deleter is an optional capability of a [Store] which deletes a persisted execution.
+type Deleter interface {
+	Delete(ctx context.Context, id ExecutionID) error
+}
+
+// ErrRevisionConflict is returned by a [CompareAndSwapper] when the persisted revision
+// of the execution doesn't match the expected revision.
+var ErrRevisionConflict = errors.New("execution revision conflict")
+
+// CompareAndSwapper is an optional capability of a [Store] which persists the state only
+// when the revision of the persisted execution matches [ExecutionState.Revision] of the
+// passed state. A missing execution has the revision 0.
+type CompareAndSwapper[T any] interface {
+	CompareAndSwap(ctx context.Context, id ExecutionID, state ExecutionState[T]) error
+}
+
 // ExecutionID is a compound ID of a unique ID passed by the modules callers
 // and name of the flow that is being executed.
 type ExecutionID struct {
@@ -21,55 +57,175 @@ type ExecutionID struct {
 type ExecutionState[T any] struct {
 	CheckpointState  CheckpointState `json:"checkpoint_state"`
 	ApplicationState T               `json:"application_state"`
+	// SchemaVersion is the version of the application state schema this state was
+	// persisted with. See [Pipe.WithSchemaVersion].
+	SchemaVersion int `json:"schema_version"`
+	// Revision is incremented by one by the store every time the state is persisted.
+	// See [CompareAndSwapper].
+	Revision uint64 `json:"revision"`
 }
 
+// RawExecutionState is an [ExecutionState] with the application state left undecoded.
+type RawExecutionState = ExecutionState[json.RawMessage]
+
+// RawStore is an optional capability of a [Store] which returns the persisted state without
+// decoding the application state. Serializing stores should implement it so that the
+// registered [Migration]s can upgrade payloads written with an older version of the state struct.
+type RawStore interface {
+	GetRaw(ctx context.Context, id ExecutionID) (RawExecutionState, error)
+}
+
+// ExecutionStatus is the status of a persisted execution.
+type ExecutionStatus string
+
+const (
+	// StatusRunning is set while the flow is executing the graph nodes.
+	StatusRunning ExecutionStatus = "running"
+	// StatusInterrupted is set when the flow is waiting on a HITL interrupt.
+	StatusInterrupted ExecutionStatus = "interrupted"
+	// StatusCompleted is set when the flow reached the end of the graph.
+	StatusCompleted ExecutionStatus = "completed"
+	// StatusExpired is set when an interrupted execution wasn't resumed in time.
+	// Expired executions can't be resumed.
+	StatusExpired ExecutionStatus = "expired"
+	// StatusWaiting is set when the flow is waiting on a signal, see [Pipe.Signal].
+	StatusWaiting ExecutionStatus = "waiting"
+	// StatusSleeping is set when the flow is sleeping until a timer is due, see [Sleep].
+	StatusSleeping ExecutionStatus = "sleeping"
+	// StatusFailed is set when an expired interrupt failed the execution, see [TimeoutFail].
+	// Failed executions can't be resumed.
+	StatusFailed ExecutionStatus = "failed"
+)
+
 // CheckpointState stores flow execution state which will be used to resume
 // when the execution is interrupted.
 type CheckpointState struct {
 	// CheckpointID is the name of the graph node which will be picked up next when
 	// the flow is executed.
 	CheckpointID string `json:"checkpoint_id"`
-	// Visited stores all the visited graph node (node IDs).
-	Visited []string `json:"visited"`
+	// Attempt is the number of times the node at the current step was executed.
+	// It's incremented every time an interrupted node is resumed.
+	Attempt int `json:"attempt"`
+	// GraphVersion is the version of the graph this execution was started with.
+	GraphVersion string `json:"graph_version"`
+	// Visited stores the visited graph nodes (node IDs) in a compact form.
+	Visited VisitLog `json:"visited"`
 	// Interrupt stores the Human in the loop interrupt when any node return a HITLInterrupt error.
 	Interrupt HITLInterrupt `json:"interrupt"`
 	// InterruptHistory stores all the resolved HITL interrupts.
 	InterruptHistory []ResolvedHITLInterrupt `json:"interrupt_history"`
+	// Signal is the signal the execution is waiting on.
+	Signal SignalWait `json:"signal,omitzero"`
+	// SignalHistory is the list of signals received by the execution.
+	SignalHistory []ReceivedSignal `json:"signal_history,omitempty"`
+	// Timer is the timer the execution is sleeping on.
+	Timer TimerWait `json:"timer,omitzero"`
+	// TimerHistory is the list of timers which fired for the execution.
+	TimerHistory []FiredTimer `json:"timer_history,omitempty"`
+	// Status is the status of the execution.
+	Status ExecutionStatus `json:"status"`
+	// CreatedAt is the time the execution was started.
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is the time the execution state was last persisted.
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // ResolvedHITLInterrupt contains the original HITL interrupt and the answer values submitted by the user.
 type ResolvedHITLInterrupt struct {
 	HITLInterrupt
 	Values map[string]string
+	// Step is the step (number of visits) of the execution the interrupt was resolved in.
+	Step int `json:"step,omitempty"`
+	// Resolution describes how the interrupt was resolved.
+	Resolution Resolution `json:"resolution,omitempty"`
+	// ResolvedBy identifies who resolved the interrupt, see [ResumeConfig.Resolver]. It's
+	// empty for interrupts resolved automatically.
+	ResolvedBy Principal `json:"resolved_by,omitzero"`
+	// ResolvedAt is the time the interrupt was resolved.
+	ResolvedAt time.Time `json:"resolved_at,omitzero"`
 }
 
+// Resolution describes how an interrupt was resolved.
+type Resolution string
+
+const (
+	// ResolutionAnswered is set for interrupts resolved with the values passed to [Pipe.Continue].
+	ResolutionAnswered Resolution = "answered"
+	// ResolutionTimeout is set for expired interrupts resolved by their [TimeoutPolicy].
+	ResolutionTimeout Resolution = "timeout"
+)
+
 // InMemoryStore implements the [Store] interface to store the checkpointing data in a in-memory map.
 type InMemoryStore[T any] struct {
 	// Disclaimer: This struct and it's methods are AI Generated, not the documentation.
-	states map[string]ExecutionState[T]
+	mu     sync.RWMutex
+	states map[ExecutionID]ExecutionState[T]
 }
 
 // NewInMemoryStore create a new [InMemoryStore].
 func NewInMemoryStore[T any]() *InMemoryStore[T] {
 	return &InMemoryStore[T]{
-		states: make(map[string]ExecutionState[T]),
+		states: make(map[ExecutionID]ExecutionState[T]),
 	}
 }
 
 // Get implements the [Store.Get] method of the [Store] interface.
 func (s *InMemoryStore[T]) Get(ctx context.Context, id ExecutionID) (ExecutionState[T], error) {
-	key := id.ID + ":" + id.FlowName
-	state, ok := s.states[key]
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[id]
 	if !ok {
 		var zero ExecutionState[T]
-		return zero, nil
+		return zero, ErrExecutionNotFound
 	}
 	return state, nil
 }
 
 // Set implements the [Store.Set] method of the [Store] interface.
 func (s *InMemoryStore[T]) Set(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
-	key := id.ID + ":" + id.FlowName
-	s.states[key] = state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state.Revision = s.states[id].Revision + 1
+	s.states[id] = state
+	return nil
+}
+
+// CompareAndSwap implements the [CompareAndSwapper] interface for [InMemoryStore].
+func (s *InMemoryStore[T]) CompareAndSwap(ctx context.Context, id ExecutionID, state ExecutionState[T]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.states[id].Revision
+	if current != state.Revision {
+		return ErrRevisionConflict
+	}
+
+	state.Revision = current + 1
+	s.states[id] = state
+	return nil
+}
+
+// List implements the [Lister] interface for [InMemoryStore].
+func (s *InMemoryStore[T]) List(ctx context.Context, flowName string) ([]ExecutionID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]ExecutionID, 0, len(s.states))
+	for id := range s.states {
+		if id.FlowName == flowName {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Delete implements the [Deleter] interface for [InMemoryStore].
+func (s *InMemoryStore[T]) Delete(ctx context.Context, id ExecutionID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, id)
 	return nil
 }
@@ -0,0 +1,33 @@
+package flodk_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aki-kong/flodk"
+	"github.com/aki-kong/flodk/storetest"
+)
+
+func TestInMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) flodk.Store[storetest.State] {
+		return flodk.NewInMemoryStore[storetest.State]()
+	})
+}
+
+func TestEncryptedStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) flodk.Store[storetest.State] {
+		return flodk.NewEncryptedStore[storetest.State](
+			flodk.NewInMemoryStore[flodk.Sealed](),
+			flodk.StaticKeys{
+				Current: "k1",
+				Keys:    map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)},
+			},
+		)
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) flodk.Store[storetest.State] {
+		return flodk.NewFileStore[storetest.State](t.TempDir())
+	})
+}
@@ -0,0 +1,359 @@
+// Package storetest implements a conformance test suite for [flodk.Store] implementations.
+//
+// Run the suite from the tests of the store implementation:
+//
+//	func TestMyStore(t *testing.T) {
+//	    storetest.Run(t, func(t *testing.T) flodk.Store[storetest.State] {
+//	        return NewMyStore[storetest.State]()
+//	    })
+//	}
+//
+// The optional capabilities ([flodk.Lister], [flodk.Deleter] and [flodk.CompareAndSwapper])
+// are only tested when the store implements them.
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aki-kong/flodk"
+)
+
+// State is the application state persisted by the suite.
+type State struct {
+	Name  string         `json:"name"`
+	Count int            `json:"count"`
+	Tags  []string       `json:"tags"`
+	Meta  map[string]int `json:"meta"`
+}
+
+// NewStoreFunc creates a new empty store for every test of the suite.
+type NewStoreFunc func(t *testing.T) flodk.Store[State]
+
+// Run runs the conformance suite against the stores created by newStore.
+func Run(t *testing.T, newStore NewStoreFunc) {
+	t.Run("GetMissing", func(t *testing.T) { testGetMissing(t, newStore(t)) })
+	t.Run("RoundTrip", func(t *testing.T) { testRoundTrip(t, newStore(t)) })
+	t.Run("Overwrite", func(t *testing.T) { testOverwrite(t, newStore(t)) })
+	t.Run("Isolation", func(t *testing.T) { testIsolation(t, newStore(t)) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, newStore(t)) })
+	t.Run("List", func(t *testing.T) { testList(t, newStore(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newStore(t)) })
+	t.Run("CompareAndSwap", func(t *testing.T) { testCompareAndSwap(t, newStore(t)) })
+}
+
+// Execution returns a fully populated execution state, including a pending interrupt
+// with a validation error and a interrupt history.
+func Execution(n int) flodk.ExecutionState[State] {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	interrupt := flodk.HITLInterrupt{
+		Reason:  "journey_details_not_found",
+		Message: "Please input your journey details",
+		ValidationError: flodk.NewValidationError(errors.Join(
+			flodk.RequirementKeyNotFound("origin"),
+			flodk.RequirementInvalid("class", "first", []string{"economy", "business"}),
+		)),
+		Requirements: flodk.Requirements{
+			"origin": {Type: flodk.Custom},
+			"class":  {Type: flodk.Enum, Suggestions: []string{"economy", "business"}},
+		},
+		InterruptID: flodk.InterruptID{NodeID: "gather", ID: fmt.Sprintf("interrupt-%d", n)},
+	}
+
+	return flodk.ExecutionState[State]{
+		CheckpointState: flodk.CheckpointState{
+			CheckpointID: "gather",
+			GraphVersion: "v1",
+			Visited:      flodk.NewVisitLog("greet", "gather"),
+			Interrupt:    interrupt,
+			InterruptHistory: []flodk.ResolvedHITLInterrupt{{
+				HITLInterrupt: flodk.HITLInterrupt{
+					Reason:       "name_not_found",
+					Message:      "May I know your name?",
+					Requirements: flodk.Requirements{"name": {Type: flodk.Custom}},
+					InterruptID:  flodk.InterruptID{NodeID: "greet", ID: "greet-1"},
+				},
+				Values: map[string]string{"name": "Jane Doe"},
+			}},
+			Status:    flodk.StatusInterrupted,
+			CreatedAt: at,
+			UpdatedAt: at.Add(time.Duration(n) * time.Minute),
+		},
+		ApplicationState: State{
+			Name:  fmt.Sprintf("execution-%d", n),
+			Count: n,
+			Tags:  []string{"a", "b"},
+			Meta:  map[string]int{"n": n},
+		},
+		SchemaVersion: 1,
+	}
+}
+
+// assertEqual compares the execution states ignoring the store managed revision.
+func assertEqual(t *testing.T, want, got flodk.ExecutionState[State]) {
+	t.Helper()
+
+	want.Revision, got.Revision = 0, 0
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("execution state mismatch:\nwant: %+v\n got: %+v", want, got)
+	}
+}
+
+func testGetMissing(t *testing.T, store flodk.Store[State]) {
+	_, err := store.Get(t.Context(), flodk.ExecutionID{ID: "missing", FlowName: "flow"})
+	if !errors.Is(err, flodk.ErrExecutionNotFound) {
+		t.Errorf("expected ErrExecutionNotFound for a missing execution, got %v", err)
+	}
+}
+
+func testRoundTrip(t *testing.T, store flodk.Store[State]) {
+	id := flodk.ExecutionID{ID: "thread-1", FlowName: "flow"}
+	want := Execution(1)
+
+	if err := store.Set(t.Context(), id, want); err != nil {
+		t.Fatalf("error while setting the state: %s", err)
+	}
+
+	got, err := store.Get(t.Context(), id)
+	if err != nil {
+		t.Fatalf("error while getting the state: %s", err)
+	}
+
+	assertEqual(t, want, got)
+
+	var notFound flodk.ErrRequirmentKeyNotFound
+	if !errors.As(got.CheckpointState.Interrupt.ValidationError, &notFound) {
+		t.Error("expected the validation error to unwrap to ErrRequirmentKeyNotFound")
+	}
+}
+
+func testOverwrite(t *testing.T, store flodk.Store[State]) {
+	id := flodk.ExecutionID{ID: "thread-1", FlowName: "flow"}
+
+	if err := store.Set(t.Context(), id, Execution(1)); err != nil {
+		t.Fatalf("error while setting the state: %s", err)
+	}
+
+	want := Execution(2)
+	want.CheckpointState.Interrupt = flodk.HITLInterrupt{}
+	want.CheckpointState.Status = flodk.StatusCompleted
+	if err := store.Set(t.Context(), id, want); err != nil {
+		t.Fatalf("error while overwriting the state: %s", err)
+	}
+
+	got, err := store.Get(t.Context(), id)
+	if err != nil {
+		t.Fatalf("error while getting the state: %s", err)
+	}
+
+	assertEqual(t, want, got)
+}
+
+func testIsolation(t *testing.T, store flodk.Store[State]) {
+	ids := []flodk.ExecutionID{
+		{ID: "thread-1", FlowName: "flow_a"},
+		{ID: "thread-1", FlowName: "flow_b"},
+		{ID: "thread-2", FlowName: "flow_a"},
+	}
+
+	for i, id := range ids {
+		if err := store.Set(t.Context(), id, Execution(i)); err != nil {
+			t.Fatalf("error while setting the state of %+v: %s", id, err)
+		}
+	}
+
+	for i, id := range ids {
+		got, err := store.Get(t.Context(), id)
+		if err != nil {
+			t.Fatalf("error while getting the state of %+v: %s", id, err)
+		}
+
+		assertEqual(t, Execution(i), got)
+	}
+}
+
+func testConcurrency(t *testing.T, store flodk.Store[State]) {
+	const workers = 16
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, workers*3)
+
+	for i := range workers {
+		wg.Go(func() {
+			id := flodk.ExecutionID{ID: fmt.Sprintf("thread-%d", i), FlowName: "flow"}
+			shared := flodk.ExecutionID{ID: "shared", FlowName: "flow"}
+
+			errs <- store.Set(t.Context(), id, Execution(i))
+			errs <- store.Set(t.Context(), shared, Execution(i))
+			_, err := store.Get(t.Context(), shared)
+			errs <- err
+		})
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("error during concurrent access: %s", err)
+		}
+	}
+
+	for i := range workers {
+		got, err := store.Get(t.Context(), flodk.ExecutionID{ID: fmt.Sprintf("thread-%d", i), FlowName: "flow"})
+		if err != nil {
+			t.Fatalf("error while getting the state: %s", err)
+		}
+
+		assertEqual(t, Execution(i), got)
+	}
+
+	shared, err := store.Get(t.Context(), flodk.ExecutionID{ID: "shared", FlowName: "flow"})
+	if err != nil {
+		t.Fatalf("error while getting the shared state: %s", err)
+	}
+
+	if shared.ApplicationState.Name == "" {
+		t.Error("expected the shared execution to hold one of the written states")
+	}
+}
+
+func testList(t *testing.T, store flodk.Store[State]) {
+	lister, ok := store.(flodk.Lister)
+	if !ok {
+		t.Skip("store doesn't implement flodk.Lister")
+	}
+
+	want := []flodk.ExecutionID{
+		{ID: "thread-1", FlowName: "flow_a"},
+		{ID: "thread-2", FlowName: "flow_a"},
+	}
+
+	for i, id := range append(want, flodk.ExecutionID{ID: "thread-1", FlowName: "flow_b"}) {
+		if err := store.Set(t.Context(), id, Execution(i)); err != nil {
+			t.Fatalf("error while setting the state of %+v: %s", id, err)
+		}
+	}
+
+	got, err := lister.List(t.Context(), "flow_a")
+	if err != nil {
+		t.Fatalf("error while listing the executions: %s", err)
+	}
+
+	slices.SortFunc(got, func(a, b flodk.ExecutionID) int {
+		if a.ID < b.ID {
+			return -1
+		}
+
+		if a.ID > b.ID {
+			return 1
+		}
+
+		return 0
+	})
+
+	if !slices.Equal(want, got) {
+		t.Errorf("expected executions %v, got %v", want, got)
+	}
+}
+
+func testDelete(t *testing.T, store flodk.Store[State]) {
+	deleter, ok := store.(flodk.Deleter)
+	if !ok {
+		t.Skip("store doesn't implement flodk.Deleter")
+	}
+
+	id := flodk.ExecutionID{ID: "thread-1", FlowName: "flow"}
+	other := flodk.ExecutionID{ID: "thread-1", FlowName: "other_flow"}
+
+	for _, id := range []flodk.ExecutionID{id, other} {
+		if err := store.Set(t.Context(), id, Execution(1)); err != nil {
+			t.Fatalf("error while setting the state: %s", err)
+		}
+	}
+
+	if err := deleter.Delete(t.Context(), id); err != nil {
+		t.Fatalf("error while deleting the execution: %s", err)
+	}
+
+	if _, err := store.Get(t.Context(), id); !errors.Is(err, flodk.ErrExecutionNotFound) {
+		t.Errorf("expected ErrExecutionNotFound for a deleted execution, got %v", err)
+	}
+
+	got, err := store.Get(t.Context(), other)
+	if err != nil {
+		t.Fatalf("error while getting the state: %s", err)
+	}
+
+	assertEqual(t, Execution(1), got)
+
+	if err := deleter.Delete(t.Context(), id); err != nil {
+		t.Errorf("expected deleting a missing execution to succeed, got %s", err)
+	}
+
+	if lister, ok := store.(flodk.Lister); ok {
+		ids, err := lister.List(t.Context(), "flow")
+		if err != nil {
+			t.Fatalf("error while listing the executions: %s", err)
+		}
+
+		if len(ids) != 0 {
+			t.Errorf("expected no executions after delete, got %v", ids)
+		}
+	}
+}
+
+func testCompareAndSwap(t *testing.T, store flodk.Store[State]) {
+	cas, ok := store.(flodk.CompareAndSwapper[State])
+	if !ok {
+		t.Skip("store doesn't implement flodk.CompareAndSwapper")
+	}
+
+	id := flodk.ExecutionID{ID: "thread-1", FlowName: "flow"}
+
+	// A missing execution has the revision 0.
+	if err := cas.CompareAndSwap(t.Context(), id, Execution(1)); err != nil {
+		t.Fatalf("error while creating the execution: %s", err)
+	}
+
+	current, err := store.Get(t.Context(), id)
+	if err != nil {
+		t.Fatalf("error while getting the state: %s", err)
+	}
+
+	if current.Revision == 0 {
+		t.Fatal("expected the revision to be incremented")
+	}
+
+	stale := current
+	next := Execution(2)
+	next.Revision = current.Revision
+	if err := cas.CompareAndSwap(t.Context(), id, next); err != nil {
+		t.Fatalf("error while swapping the state: %s", err)
+	}
+
+	stale.ApplicationState.Name = "stale"
+	if err := cas.CompareAndSwap(t.Context(), id, stale); !errors.Is(err, flodk.ErrRevisionConflict) {
+		t.Errorf("expected ErrRevisionConflict for a stale revision, got %v", err)
+	}
+
+	got, err := store.Get(t.Context(), id)
+	if err != nil {
+		t.Fatalf("error while getting the state: %s", err)
+	}
+
+	assertEqual(t, Execution(2), got)
+
+	if err := store.Set(t.Context(), id, Execution(3)); err != nil {
+		t.Fatalf("error while setting the state: %s", err)
+	}
+
+	if err := cas.CompareAndSwap(t.Context(), id, next); !errors.Is(err, flodk.ErrRevisionConflict) {
+		t.Errorf("expected Set to increment the revision, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNoPendingTimer is returned when waking an execution which isn't sleeping.
+	ErrNoPendingTimer = errors.New("execution isn't sleeping")
+	// ErrTimerNotDue is returned when waking an execution before its wake up time.
+	ErrTimerNotDue = errors.New("timer isn't due")
+)
+
+// TimerWait is returned by nodes to suspend the execution until the wake up time, see [Sleep].
+// Sleeping executions are resumed by [Pipe.Wake], usually called by a [Scheduler].
+type TimerWait struct {
+	NodeID string    `json:"node_id"`
+	Name   string    `json:"name"`
+	WakeAt time.Time `json:"wake_at"`
+}
+
+// Error implements the error interface for the timer wait.
+func (tw TimerWait) Error() string {
+	return fmt.Sprintf("flow sleeping until %s: %s", tw.WakeAt.Format(time.RFC3339), tw.Name)
+}
+
+// suspend implements the suspension interface for [TimerWait].
+func (tw TimerWait) suspend(cs *CheckpointState) {
+	cs.Timer = tw
+	cs.Status = StatusSleeping
+}
+
+// FiredTimer contains a timer which was due and the time the execution was woken up.
+type FiredTimer struct {
+	TimerWait
+	// Step is the step (number of visits) of the execution the timer fired in.
+	Step int `json:"step,omitempty"`
+	// FiredAt is the time the execution was woken up.
+	FiredAt time.Time `json:"fired_at,omitzero"`
+}
+
+// timerKey is the context key of a fired timer of a graph node.
+type timerKey struct {
+	nodeID string
+	name   string
+}
+
+// loadTimer loads the context with the fired timer, keyed by its node ID and name.
+func loadTimer(ctx context.Context, ft FiredTimer) context.Context {
+	return context.WithValue(ctx, timerKey{nodeID: ft.NodeID, name: ft.Name}, ft)
+}
+
+// getLoadedTimer returns the fired timer of a node from the context.
+func getLoadedTimer(ctx context.Context, nodeID string, name string) (FiredTimer, bool) {
+	ft, ok := ctx.Value(timerKey{nodeID: nodeID, name: name}).(FiredTimer)
+	return ft, ok
+}
+
+// Sleep suspends the execution for the duration, measured using the clock of the context
+// (see [GetClock]). Until the named timer fires a [TimerWait] is returned as the error, which
+// the node must return to suspend the execution. No goroutine is held while sleeping, the
+// wake up time is persisted in the checkpoint state.
+//
+//	if err := flodk.Sleep(ctx, "reminder", 24*time.Hour); err != nil {
+//		return state, err
+//	}
+func Sleep(ctx context.Context, name string, d time.Duration) error {
+	return SleepUntil(ctx, name, GetClock(ctx).Now().Add(d))
+}
+
+// SleepUntil suspends the execution until the wake up time, see [Sleep].
+func SleepUntil(ctx context.Context, name string, wakeAt time.Time) error {
+	nodeID, ok := GetNodeID(ctx)
+	if !ok {
+		return ErrNodeIDNotFound
+	}
+
+	if _, ok := getLoadedTimer(ctx, nodeID, name); ok {
+		return nil
+	}
+
+	if !GetClock(ctx).Now().Before(wakeAt) {
+		return nil
+	}
+
+	return TimerWait{
+		NodeID: nodeID,
+		Name:   name,
+		WakeAt: wakeAt,
+	}
+}
+
+// SleepNode returns a [Node] which sleeps for the duration, see [Sleep].
+func SleepNode[T any](name string, d time.Duration) Node[T] {
+	return FunctionNode[T](func(ctx context.Context, state T) (T, error) {
+		return state, Sleep(ctx, name, d)
+	})
+}
+
+// Wake resumes the sleeping execution once its timer is due. [ErrNoPendingTimer] is returned
+// for executions which aren't sleeping and [ErrTimerNotDue] for timers which aren't due yet.
+// When the store implements [CompareAndSwapper], concurrent wakes resume the execution only
+// once, the others get [ErrNoPendingTimer].
+func (p *Pipe[T]) Wake(ctx context.Context, id string) (T, error) {
+	execState, err := p.load(ctx, ExecutionID{
+		ID:       id,
+		FlowName: p.name,
+	})
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	return p.wake(ctx, id, execState)
+}
+
+// wake resumes the loaded sleeping execution.
+func (p *Pipe[T]) wake(ctx context.Context, id string, execState ExecutionState[T]) (T, error) {
+	cs := execState.CheckpointState
+	now := p.now()
+
+	switch {
+	case cs.Status == StatusExpired:
+		return execState.ApplicationState, ErrExecutionExpired
+	case cs.Status == StatusFailed:
+		return execState.ApplicationState, ErrExecutionFailed
+	case cs.Status != StatusSleeping || cs.Timer.NodeID == "":
+		return execState.ApplicationState, ErrNoPendingTimer
+	case now.Before(cs.Timer.WakeAt):
+		return execState.ApplicationState, ErrTimerNotDue
+	}
+
+	graph, err := p.graphFor(cs)
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	claimed := execState
+	claimed.CheckpointState.Status = StatusRunning
+	release, err := p.claim(ctx, id, execState, claimed)
+	if errors.Is(err, ErrRevisionConflict) {
+		return execState.ApplicationState, ErrNoPendingTimer
+	}
+
+	if err != nil {
+		return execState.ApplicationState, err
+	}
+
+	state, err := p.invoke(loadTimer(ctx, FiredTimer{
+		TimerWait: cs.Timer,
+		FiredAt:   now,
+	}), id, graph, cs, execState.ApplicationState)
+	return state, release(err)
+}
+
+// WakeDue resumes the executions of the pipe whose timers are due and returns the number of
+// executions woken up. All the due executions are woken up even when resuming one of them
+// fails, and the errors are joined. The store must implement the [Lister] interface.
+func (p *Pipe[T]) WakeDue(ctx context.Context) (int, error) {
+	woken := 0
+	errs := []error{}
+	now := p.now()
+
+	err := p.forEachExecution(ctx, func(id ExecutionID, state ExecutionState[T]) error {
+		cs := state.CheckpointState
+		if cs.Status != StatusSleeping || now.Before(cs.Timer.WakeAt) {
+			return nil
+		}
+
+		_, err := p.wake(ctx, id.ID, state)
+		if errors.Is(err, ErrNoPendingTimer) {
+			// Woken up concurrently, e.g. by another scheduler.
+			return nil
+		}
+
+		// Executions suspended again, e.g. on the next timer, were woken up as well.
+		if ignoreSuspension(err) != nil {
+			errs = append(errs, fmt.Errorf("execution %s: %w", id.ID, err))
+			return nil
+		}
+
+		woken++
+		return nil
+	})
+
+	return woken, errors.Join(append(errs, err)...)
+}
+
+// ScheduleReport summarizes the executions resumed by a [Scheduler].
+type ScheduleReport struct {
+	Woken   int
+	Expired ExpiryReport
+}
+
+// Scheduler resumes the executions of a set of pipes whose timers are due (see [Pipe.WakeDue])
+// and resolves their expired interrupts (see [Pipe.ProcessExpired]), either on demand using
+// [Scheduler.RunOnce] or periodically using [Scheduler.Run]. Time is measured with the clock
+// of each pipe. Several schedulers may run concurrently on stores implementing
+// [CompareAndSwapper], each execution is resumed by one of them only.
+type Scheduler[T any] struct {
+	pipes []*Pipe[T]
+}
+
+// NewScheduler creates a new [Scheduler] for the passed pipes.
+func NewScheduler[T any](pipes ...*Pipe[T]) *Scheduler[T] {
+	return &Scheduler[T]{
+		pipes: pipes,
+	}
+}
+
+// RunOnce resumes the due executions of all the pipes of the scheduler once. All the pipes
+// are processed even when one of them fails, and the errors are joined.
+func (s *Scheduler[T]) RunOnce(ctx context.Context) (ScheduleReport, error) {
+	report := ScheduleReport{}
+	errs := []error{}
+
+	for _, p := range s.pipes {
+		woken, err := p.WakeDue(ctx)
+		report.Woken += woken
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		expired, err := p.ProcessExpired(ctx)
+		report.Expired.Add(expired)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// Run resumes the due executions every interval until the context is cancelled. Errors are
+// passed to the onError callback if it's not nil. [ErrInvalidInterval] is returned for
+// intervals which aren't positive.
+func (s *Scheduler[T]) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	return runEvery(ctx, interval, func(ctx context.Context) error {
+		_, err := s.RunOnce(ctx)
+		return err
+	}, onError)
+}
@@ -0,0 +1,190 @@
+package flodk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSchedulerWakesDueTimers(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("start", AdderNode(1)).
+		AddNode("wait", SleepNode[State]("reminder", 24*time.Hour)).
+		AddNode("remind", AdderNode(10)).
+		AddEdge("start", "wait").
+		AddEdge("wait", "remind").
+		SetStartNode("start").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("reminders", graph, store).WithClock(clock)
+	scheduler := NewScheduler(pipe)
+
+	var wait TimerWait
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); !errors.As(err, &wait) {
+		t.Fatalf("expected timer wait, got %v", err)
+	}
+
+	if want := clock.Now().Add(24 * time.Hour); !wait.WakeAt.Equal(want) {
+		t.Errorf("expected wake up at %s, got %s", want, wait.WakeAt)
+	}
+
+	if _, err := pipe.Wake(t.Context(), "thread-1"); !errors.Is(err, ErrTimerNotDue) {
+		t.Errorf("expected ErrTimerNotDue, got %v", err)
+	}
+
+	clock.Advance(23 * time.Hour)
+	if report, err := scheduler.RunOnce(t.Context()); err != nil || report.Woken != 0 {
+		t.Fatalf("expected no executions to wake up, got %+v with %v", report, err)
+	}
+
+	clock.Advance(time.Hour)
+	if report, err := scheduler.RunOnce(t.Context()); err != nil || report.Woken != 1 {
+		t.Fatalf("expected one execution to wake up, got %+v with %v", report, err)
+	}
+
+	es, err := store.Get(t.Context(), ExecutionID{ID: "thread-1", FlowName: "reminders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := es.CheckpointState
+	if cs.Status != StatusCompleted || es.ApplicationState.sum != 11 || len(cs.TimerHistory) != 1 {
+		t.Errorf("unexpected execution state: %+v", es)
+	}
+
+	if _, err := pipe.Wake(t.Context(), "thread-1"); !errors.Is(err, ErrNoPendingTimer) {
+		t.Errorf("expected ErrNoPendingTimer, got %v", err)
+	}
+}
+
+func TestSleepSequence(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("retry", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			for _, name := range []string{"first", "second"} {
+				if err := Sleep(ctx, name, time.Hour); err != nil {
+					return state, err
+				}
+			}
+
+			state.sum++
+			return state, nil
+		})).
+		SetStartNode("retry").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	pipe := NewPipe("retries", graph, NewInMemoryStore[State]()).WithClock(clock)
+
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected timer wait, got nil")
+	}
+
+	clock.Advance(time.Hour)
+
+	var wait TimerWait
+	if _, err := pipe.Wake(t.Context(), "thread-1"); !errors.As(err, &wait) || wait.Name != "second" {
+		t.Fatalf("expected the second timer, got %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	if state, err := pipe.Wake(t.Context(), "thread-1"); err != nil || state.sum != 1 {
+		t.Errorf("expected sum 1, got %d with %v", state.sum, err)
+	}
+}
+
+func TestWakeDueCountsWokenExecutions(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("wait", SleepNode[State]("reminder", time.Hour)).
+		AddNode("remind", AdderNode(10)).
+		AddEdge("wait", "remind").
+		SetStartNode("wait").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := NewInMemoryStore[State]()
+	pipe := NewPipe("reminders", graph, store).WithClock(clock)
+
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the execution to sleep")
+	}
+
+	// An execution started on a graph version the pipe doesn't know.
+	_ = store.Set(t.Context(), ExecutionID{ID: "thread-2", FlowName: "reminders"}, ExecutionState[State]{
+		CheckpointState: CheckpointState{
+			CheckpointID: "wait",
+			GraphVersion: "unknown",
+			Status:       StatusSleeping,
+			Timer:        TimerWait{NodeID: "wait", Name: "reminder", WakeAt: clock.Now()},
+		},
+	})
+
+	clock.Advance(time.Hour)
+	woken, err := pipe.WakeDue(t.Context())
+
+	var versionErr GraphVersionNotFoundError
+	if !errors.As(err, &versionErr) {
+		t.Errorf("expected GraphVersionNotFoundError, got %v", err)
+	}
+
+	if woken != 1 {
+		t.Errorf("expected one execution to wake up, got %d", woken)
+	}
+
+	if err := NewScheduler(pipe).Run(t.Context(), 0, nil); !errors.Is(err, ErrInvalidInterval) {
+		t.Errorf("expected ErrInvalidInterval, got %v", err)
+	}
+}
+
+func TestWakeDueConcurrently(t *testing.T) {
+	reminded := 0
+	graph, err := NewGraphBuilder[State]().
+		AddNode("wait", SleepNode[State]("reminder", time.Hour)).
+		AddNode("remind", FunctionNode[State](func(ctx context.Context, state State) (State, error) {
+			reminded++
+			return state, nil
+		})).
+		AddEdge("wait", "remind").
+		SetStartNode("wait").
+		Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	clock := NewManualClock(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC))
+	store := &racingStore[State]{InMemoryStore: NewInMemoryStore[State]()}
+	pipe := NewPipe("reminders", graph, store).WithClock(clock)
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err == nil {
+		t.Fatal("expected the execution to sleep")
+	}
+
+	clock.Advance(time.Hour)
+
+	concurrent := 0
+	store.race = func() {
+		var err error
+		if concurrent, err = pipe.WakeDue(t.Context()); err != nil {
+			t.Errorf("error while waking up the executions concurrently: %s", err)
+		}
+	}
+
+	woken, err := pipe.WakeDue(t.Context())
+	if err != nil {
+		t.Fatalf("error while waking up the executions: %s", err)
+	}
+
+	if woken+concurrent != 1 || reminded != 1 {
+		t.Errorf("expected the execution to wake up once, got %d woken and %d reminders", woken+concurrent, reminded)
+	}
+}
@@ -0,0 +1,248 @@
+package flodk
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+)
+
+// DefaultVisitLimit is the default number of visit runs kept in a [VisitLog].
+const DefaultVisitLimit = 256
+
+// VisitRun is a run of consecutive visits of the same graph node.
+type VisitRun struct {
+	NodeID string `json:"node_id"`
+	Count  int    `json:"count"`
+}
+
+// VisitLog is a compact record of the visited graph nodes. Consecutive visits of the same
+// node are run-length encoded and only the most recent runs are kept, so that looping flows
+// don't grow the checkpoint without bound. The per node counters and the total number of
+// visits always cover the whole execution. Use a [HistoryStore] to keep the full history.
+type VisitLog struct {
+	// Runs are the most recent visit runs, oldest first.
+	Runs []VisitRun `json:"runs"`
+	// Counts is the number of visits per node.
+	Counts map[string]int `json:"counts"`
+	// Total is the total number of visits.
+	Total int `json:"total"`
+	// Dropped is the number of visits dropped from the Runs because of the limit.
+	Dropped int `json:"dropped"`
+}
+
+// NewVisitLog creates a [VisitLog] for the passed sequence of visited nodes.
+func NewVisitLog(nodeIDs ...string) VisitLog {
+	vl := VisitLog{
+		Runs:   make([]VisitRun, 0),
+		Counts: make(map[string]int),
+	}
+
+	for _, nodeID := range nodeIDs {
+		vl.Append(nodeID, 0)
+	}
+
+	return vl
+}
+
+// Append records a visit of the node. When the number of runs exceeds the limit, the oldest
+// runs are dropped. A limit less than or equal to 0 keeps all the runs.
+func (vl *VisitLog) Append(nodeID string, limit int) {
+	if vl.Counts == nil {
+		vl.Counts = make(map[string]int)
+	}
+
+	vl.Counts[nodeID]++
+	vl.Total++
+
+	if n := len(vl.Runs); n > 0 && vl.Runs[n-1].NodeID == nodeID {
+		vl.Runs[n-1].Count++
+		return
+	}
+
+	vl.Runs = append(vl.Runs, VisitRun{NodeID: nodeID, Count: 1})
+	if limit > 0 && len(vl.Runs) > limit {
+		drop := len(vl.Runs) - limit
+		for _, run := range vl.Runs[:drop] {
+			vl.Dropped += run.Count
+		}
+
+		vl.Runs = append([]VisitRun(nil), vl.Runs[drop:]...)
+	}
+}
+
+// Nodes returns the visited node IDs of the kept runs in the order they were visited.
+// This is the complete history unless [VisitLog.Dropped] is greater than 0.
+func (vl VisitLog) Nodes() []string {
+	nodes := make([]string, 0, vl.Total-vl.Dropped)
+	for _, run := range vl.Runs {
+		for range run.Count {
+			nodes = append(nodes, run.NodeID)
+		}
+	}
+
+	return nodes
+}
+
+// Last returns the last visited node ID.
+func (vl VisitLog) Last() string {
+	if len(vl.Runs) == 0 {
+		return ""
+	}
+
+	return vl.Runs[len(vl.Runs)-1].NodeID
+}
+
+// Len returns the total number of visits.
+func (vl VisitLog) Len() int {
+	return vl.Total
+}
+
+// Count returns the number of visits of the node.
+func (vl VisitLog) Count(nodeID string) int {
+	return vl.Counts[nodeID]
+}
+
+// Remap returns a copy of the visit log with the node IDs renamed by the passed function.
+func (vl VisitLog) Remap(fn func(nodeID string) string) VisitLog {
+	remapped := VisitLog{
+		Runs:    make([]VisitRun, 0, len(vl.Runs)),
+		Counts:  make(map[string]int, len(vl.Counts)),
+		Total:   vl.Total,
+		Dropped: vl.Dropped,
+	}
+
+	for _, run := range vl.Runs {
+		run.NodeID = fn(run.NodeID)
+		if n := len(remapped.Runs); n > 0 && remapped.Runs[n-1].NodeID == run.NodeID {
+			remapped.Runs[n-1].Count += run.Count
+			continue
+		}
+
+		remapped.Runs = append(remapped.Runs, run)
+	}
+
+	for nodeID, count := range vl.Counts {
+		remapped.Counts[fn(nodeID)] += count
+	}
+
+	return remapped
+}
+
+// visitLogJSON avoids the recursion of [VisitLog.UnmarshalJSON].
+type visitLogJSON VisitLog
+
+// UnmarshalJSON decodes the visit log, including the plain list of node IDs
+// persisted by older versions.
+func (vl *VisitLog) UnmarshalJSON(bs []byte) error {
+	var nodeIDs []string
+	if err := json.Unmarshal(bs, &nodeIDs); err == nil {
+		*vl = NewVisitLog(nodeIDs...)
+		return nil
+	}
+
+	decoded := visitLogJSON{}
+	if err := json.Unmarshal(bs, &decoded); err != nil {
+		return err
+	}
+
+	*vl = VisitLog(decoded)
+
+	return nil
+}
+
+// Visit is a single entry of the full visit history of an execution.
+type Visit struct {
+	Step   int       `json:"step"`
+	NodeID string    `json:"node_id"`
+	At     time.Time `json:"at"`
+}
+
+// HistoryStore keeps the full visit history of the executions, which is compacted in the
+// [CheckpointState]. See [Pipe.WithHistoryStore].
+type HistoryStore interface {
+	AppendVisit(ctx context.Context, id ExecutionID, visit Visit) error
+	Visits(ctx context.Context, id ExecutionID) ([]Visit, error)
+}
+
+// InMemoryHistoryStore implements the [HistoryStore] interface in memory.
+type InMemoryHistoryStore struct {
+	mu     sync.RWMutex
+	visits map[ExecutionID][]Visit
+}
+
+// NewInMemoryHistoryStore creates a new [InMemoryHistoryStore].
+func NewInMemoryHistoryStore() *InMemoryHistoryStore {
+	return &InMemoryHistoryStore{
+		visits: make(map[ExecutionID][]Visit),
+	}
+}
+
+// AppendVisit implements the [HistoryStore] interface for [InMemoryHistoryStore].
+func (s *InMemoryHistoryStore) AppendVisit(ctx context.Context, id ExecutionID, visit Visit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.visits[id] = append(s.visits[id], visit)
+	return nil
+}
+
+// Visits implements the [HistoryStore] interface for [InMemoryHistoryStore].
+func (s *InMemoryHistoryStore) Visits(ctx context.Context, id ExecutionID) ([]Visit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Visit{}, s.visits[id]...), nil
+}
+
+// Delete implements the [Deleter] interface for [InMemoryHistoryStore].
+func (s *InMemoryHistoryStore) Delete(ctx context.Context, id ExecutionID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.visits, id)
+	return nil
+}
+
+// WithVisitLimit sets the number of visit runs kept in the [CheckpointState]. A limit less
+// than or equal to 0 keeps all the runs.
+func (p *Pipe[T]) WithVisitLimit(limit int) *Pipe[T] {
+	p.visitLimit = limit
+
+	return p
+}
+
+// WithHistoryStore sets the store which records the full visit history of the executions.
+func (p *Pipe[T]) WithHistoryStore(hs HistoryStore) *Pipe[T] {
+	p.history = hs
+
+	return p
+}
+
+// recordVisitFunc generates the callback which records every visit into the history store.
+func (p *Pipe[T]) recordVisitFunc(ctx context.Context, id string) FlowCallback[T] {
+	if p.history == nil {
+		return nil
+	}
+
+	return func(cs CheckpointState, runState T) error {
+		return p.history.AppendVisit(ctx, ExecutionID{
+			ID:       id,
+			FlowName: p.name,
+		}, Visit{
+			Step:   cs.Visited.Len(),
+			NodeID: cs.Visited.Last(),
+			At:     p.now(),
+		})
+	}
+}
+
+// cloneVisitLog returns a deep copy of the visit log, so that the persisted
+// checkpoint doesn't share its runs and counters with the running flow.
+func cloneVisitLog(vl VisitLog) VisitLog {
+	vl.Runs = append([]VisitRun(nil), vl.Runs...)
+	vl.Counts = maps.Clone(vl.Counts)
+
+	return vl
+}
@@ -0,0 +1,82 @@
+package flodk
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestVisitLogCompaction(t *testing.T) {
+	vl := NewVisitLog()
+	for _, nodeID := range []string{"a", "a", "b", "a", "b", "c"} {
+		vl.Append(nodeID, 3)
+	}
+
+	if want := []string{"a", "b", "c"}; !slices.Equal(vl.Nodes(), want) {
+		t.Errorf("expected kept nodes %v, got %v", want, vl.Nodes())
+	}
+
+	if vl.Len() != 6 || vl.Dropped != 3 || vl.Count("a") != 3 || vl.Last() != "c" {
+		t.Errorf("unexpected visit log: %+v", vl)
+	}
+}
+
+func TestVisitLogLegacyJSON(t *testing.T) {
+	cs := CheckpointState{}
+	if err := json.Unmarshal([]byte(`{"visited": ["a", "b", "b"]}`), &cs); err != nil {
+		t.Fatalf("error while decoding the legacy checkpoint: %s", err)
+	}
+
+	if want := []string{"a", "b", "b"}; !slices.Equal(cs.Visited.Nodes(), want) {
+		t.Errorf("expected visited %v, got %v", want, cs.Visited.Nodes())
+	}
+
+	bs, _ := json.Marshal(cs)
+	decoded := CheckpointState{}
+	if err := json.Unmarshal(bs, &decoded); err != nil {
+		t.Fatalf("error while decoding the checkpoint: %s", err)
+	}
+
+	if decoded.Visited.Count("b") != 2 || decoded.Visited.Len() != 3 {
+		t.Errorf("unexpected decoded visit log: %+v", decoded.Visited)
+	}
+}
+
+func TestPipeVisitHistory(t *testing.T) {
+	graph, err := NewGraphBuilder[State]().
+		AddNode("addition_1", AdderNode(1)).
+		AddNode("addition_2", AdderNode(2)).
+		AddNode("end", Noop[State]()).
+		AddEdge("addition_1", "addition_2").
+		AddConditionalEdge("addition_2", GtNode(30), map[string]string{
+			Continue: "addition_1",
+			End:      "end",
+		}).SetStartNode("addition_1").Build()
+	if err != nil {
+		t.Fatalf("error while building the graph: %s", err)
+	}
+
+	store := NewInMemoryStore[State]()
+	history := NewInMemoryHistoryStore()
+	pipe := NewPipe("loop", graph, store).WithVisitLimit(4).WithHistoryStore(history)
+
+	if _, err := pipe.Invoke(t.Context(), "thread-1", State{}); err != nil {
+		t.Fatalf("error while invoking the flow: %s", err)
+	}
+
+	id := ExecutionID{ID: "thread-1", FlowName: "loop"}
+	state, _ := store.Get(t.Context(), id)
+	visits, _ := history.Visits(t.Context(), id)
+
+	if len(state.CheckpointState.Visited.Runs) != 4 {
+		t.Errorf("expected 4 kept runs, got %d", len(state.CheckpointState.Visited.Runs))
+	}
+
+	if len(visits) != state.CheckpointState.Visited.Len() {
+		t.Errorf("expected %d recorded visits, got %d", state.CheckpointState.Visited.Len(), len(visits))
+	}
+
+	if visits[len(visits)-1].NodeID != "end" || visits[len(visits)-1].Step != len(visits) {
+		t.Errorf("unexpected last visit: %+v", visits[len(visits)-1])
+	}
+}